@@ -6,6 +6,7 @@ import (
 	"CROWD_MARKET/services"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -14,8 +15,22 @@ import (
 
 func main() {
 	config.InitConfig()
+	services.InitImageStore()
 	services.InitUserService()
 	services.InitProductService()
+	services.InitItemService()
+	services.InitVoteService()
+	services.InitModerationService()
+	services.InitRevisionService()
+	services.InitExchangeRateService()
+	services.InitUnitService()
+	services.InitCategoryService()
+	services.InitLocationService()
+	services.InitMarketService()
+	services.InitUploadService()
+	services.StartConfidenceRefresher(time.Hour)
+	services.StartProductPurger(time.Hour)
+	services.StartImageCollector(24 * time.Hour)
 
 	router := gin.Default()
 
@@ -35,6 +50,11 @@ func main() {
 
 	routes.RegisterRoutes(router)
 
+	// 🖼️ Serve images kept by the local image store
+	if config.ImageStore == "local" && strings.HasPrefix(config.ImageBaseURL, "/") {
+		router.Static(config.ImageBaseURL, config.ImageDir)
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/services"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// Usage: go run ./cmd/migrate [-dry-run] [-out report.json] <migration>
+var migrations = map[string]func(dryRun bool) (interface{}, error){
+	"products-to-items": func(dryRun bool) (interface{}, error) {
+		return services.MigrateProductsToItems(dryRun)
+	},
+	// Run before products-to-items on databases with float prices
+	"prices-to-money": func(dryRun bool) (interface{}, error) {
+		return services.MigratePricesToMoney(dryRun)
+	},
+	// Needs the locations collection populated first; unmatched areas are
+	// listed under needs_review
+	"areas-to-locations": func(dryRun bool) (interface{}, error) {
+		return services.MigrateAreasToLocations(dryRun)
+	},
+	// Maps free-text product categories onto taxonomy slugs, creating
+	// top-level categories for values that match none
+	"categories-to-slugs": func(dryRun bool) (interface{}, error) {
+		return services.MigrateCategoriesToSlugs(dryRun)
+	},
+	// Deletes stored images nothing references, past IMAGE_GC_GRACE_HOURS
+	"orphaned-images": func(dryRun bool) (interface{}, error) {
+		return services.CollectOrphanedImages(dryRun)
+	},
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would change without writing")
+	outPath := flag.String("out", "", "also write the JSON report to this file")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: migrate [-dry-run] [-out report.json] <migration>")
+		for name := range migrations {
+			fmt.Fprintln(os.Stderr, "  "+name)
+		}
+		os.Exit(2)
+	}
+
+	run, ok := migrations[flag.Arg(0)]
+	if !ok {
+		log.Fatalf("❌ Unknown migration %q", flag.Arg(0))
+	}
+
+	config.InitConfig()
+	services.InitImageStore()
+	services.InitProductService()
+	services.InitItemService()
+	services.InitLocationService()
+	services.InitCategoryService()
+	services.InitUploadService()
+
+	report, err := run(*dryRun)
+	if err != nil {
+		log.Fatal("❌ Migration failed: ", err)
+	}
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+	if *outPath != "" {
+		if err := os.WriteFile(*outPath, append(out, '\n'), 0o644); err != nil {
+			log.Fatal("❌ Failed to write report: ", err)
+		}
+	}
+}
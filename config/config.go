@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
@@ -22,6 +25,38 @@ var (
 	EmailPassword     string
 	GoogleOauthConfig *oauth2.Config
 	Cloud             *cloudinary.Cloudinary
+	ProductPageSize   int
+	OutlierThreshold  float64
+	OutlierWindowDays int
+	OutlierMinSamples int
+
+	ReviewReputationThreshold float64
+	ProductRestoreWindow      time.Duration
+	DefaultCurrency           string
+	MarketTimezone            *time.Location
+	MaxProductImages          int
+	ImageStore                string
+	ImageDir                  string
+	ImageBaseURL              string
+	UploadDir                 string
+	MaxImageBytes             int64
+	UploadURLTTL              time.Duration
+	UploadSigningKey          []byte
+	ImageGCGrace              time.Duration
+	MaxImportRows             int
+	MaxImportBytes            int64
+)
+
+// --- LISTING LIMITS ---
+const (
+	DefaultProductPageSize = 20
+	MaxProductPageSize     = 100
+)
+
+// --- SCORING DEFAULTS ---
+const (
+	DefaultOutlierThreshold          = 3.5
+	DefaultReviewReputationThreshold = 0.3
 )
 
 // --- INITIALIZER ---
@@ -42,6 +77,51 @@ func InitConfig() {
 		log.Println("⚠️ Warning: EMAIL_FROM or EMAIL_PASSWORD not set in .env")
 	}
 
+	// ✅ Listing page size (capped at MaxProductPageSize)
+	ProductPageSize = getEnvInt("PRODUCTS_PAGE_SIZE", DefaultProductPageSize)
+	if ProductPageSize <= 0 || ProductPageSize > MaxProductPageSize {
+		log.Printf("⚠️ PRODUCTS_PAGE_SIZE out of range, using %d", DefaultProductPageSize)
+		ProductPageSize = DefaultProductPageSize
+	}
+
+	// ✅ Price outlier scoring and review holds
+	initScoring()
+
+	// ✅ Deleted products can be restored for this long, then get purged
+	ProductRestoreWindow = time.Duration(getEnvInt("PRODUCT_RESTORE_DAYS", 30)) * 24 * time.Hour
+
+	// ✅ Currency assumed for prices entered without one (ISO 4217)
+	DefaultCurrency = strings.ToUpper(getEnv("DEFAULT_CURRENCY", "NGN"))
+
+	// ✅ Time zone for market opening hours that do not name their own
+	MarketTimezone = getEnvLocation("MARKET_TIMEZONE", "Africa/Lagos")
+
+	// ✅ Photos a single product may carry
+	MaxProductImages = getEnvInt("MAX_PRODUCT_IMAGES", 8)
+
+	// ✅ Image storage: "cloudinary", or "local" to keep files in IMAGE_DIR
+	// served under IMAGE_BASE_URL. Unprocessed direct uploads to the local
+	// store wait in UPLOAD_DIR, which is never served. There is no S3
+	// backend.
+	ImageStore = strings.ToLower(getEnv("IMAGE_STORE", "cloudinary"))
+	ImageDir = getEnv("IMAGE_DIR", "uploads")
+	ImageBaseURL = strings.TrimRight(getEnv("IMAGE_BASE_URL", "/uploads"), "/")
+	UploadDir = getEnv("UPLOAD_DIR", "incoming")
+	MaxImageBytes = int64(getEnvInt("MAX_IMAGE_MB", 10)) << 20
+
+	// ✅ Signed direct uploads: how long a slot stays open, and the key
+	// signing local upload URLs
+	UploadURLTTL = time.Duration(getEnvInt("UPLOAD_URL_MINUTES", 15)) * time.Minute
+	UploadSigningKey = []byte(getEnv("UPLOAD_SIGNING_SECRET", os.Getenv("JWT_SECRET")))
+
+	// ✅ Unreferenced images younger than this are left alone by the image
+	// collector, so uploads for products still being saved survive
+	ImageGCGrace = time.Duration(getEnvInt("IMAGE_GC_GRACE_HOURS", 24)) * time.Hour
+
+	// ✅ Bulk imports of price reports (POST /imports)
+	MaxImportRows = getEnvInt("MAX_IMPORT_ROWS", 5000)
+	MaxImportBytes = int64(getEnvInt("MAX_IMPORT_MB", 10)) << 20
+
 	// ✅ Connect MongoDB
 	connectMongoDB()
 
@@ -52,6 +132,71 @@ func InitConfig() {
 	initCloudinary()
 }
 
+// --- SCORING ---
+func initScoring() {
+	// ✅ Price outlier scoring; the threshold also scales reputation, so it
+	// must be positive
+	OutlierThreshold = getEnvFloat("OUTLIER_THRESHOLD", DefaultOutlierThreshold)
+	if OutlierThreshold <= 0 {
+		log.Printf("⚠️ OUTLIER_THRESHOLD must be positive, using %g", DefaultOutlierThreshold)
+		OutlierThreshold = DefaultOutlierThreshold
+	}
+	OutlierWindowDays = getEnvInt("OUTLIER_WINDOW_DAYS", 30)
+	OutlierMinSamples = getEnvInt("OUTLIER_MIN_SAMPLES", 5)
+
+	// ✅ Reports from contributors below this reputation are held for review.
+	// Reputation is in [0,1], and so must the threshold be.
+	ReviewReputationThreshold = getEnvFloat("REVIEW_REPUTATION_THRESHOLD", DefaultReviewReputationThreshold)
+	if ReviewReputationThreshold < 0 || ReviewReputationThreshold > 1 {
+		log.Printf("⚠️ REVIEW_REPUTATION_THRESHOLD must be between 0 and 1, using %g", DefaultReviewReputationThreshold)
+		ReviewReputationThreshold = DefaultReviewReputationThreshold
+	}
+}
+
+// --- ENV HELPERS ---
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvLocation(key, fallback string) *time.Location {
+	name := getEnv(key, fallback)
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("⚠️ Invalid %s=%q, using UTC: %v", key, name, err)
+		return time.UTC
+	}
+	return location
+}
+
+func getEnvInt(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Printf("⚠️ Invalid %s=%q, using %d", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		log.Printf("⚠️ Invalid %s=%q, using %g", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
 // --- MONGO DATABASE CONNECTION ---
 func connectMongoDB() {
 	uri := os.Getenv("MONGO_URI")
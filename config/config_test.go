@@ -0,0 +1,79 @@
+package config
+
+import "testing"
+
+func TestGetEnvFloat(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want float64
+	}{
+		{"", 1.5},
+		{"2.5", 2.5},
+		{"-1", -1},
+		{"abc", 1.5},
+		{"NaN", 1.5},
+		{"nan", 1.5},
+		{"Inf", 1.5},
+		{"-Infinity", 1.5},
+		{"1e400", 1.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			t.Setenv("TEST_FLOAT", tt.raw)
+			if got := getEnvFloat("TEST_FLOAT", 1.5); got != tt.want {
+				t.Errorf("getEnvFloat = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutlierThreshold(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want float64
+	}{
+		{"", DefaultOutlierThreshold},
+		{"2", 2},
+		{"0", DefaultOutlierThreshold},
+		{"-3", DefaultOutlierThreshold},
+		{"NaN", DefaultOutlierThreshold},
+		{"+Inf", DefaultOutlierThreshold},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			t.Setenv("OUTLIER_THRESHOLD", tt.raw)
+			initScoring()
+			if OutlierThreshold != tt.want {
+				t.Errorf("OutlierThreshold = %g, want %g", OutlierThreshold, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewReputationThreshold(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want float64
+	}{
+		{"", DefaultReviewReputationThreshold},
+		{"0", 0},
+		{"0.45", 0.45},
+		{"1", 1},
+		{"1.5", DefaultReviewReputationThreshold},
+		{"-0.1", DefaultReviewReputationThreshold},
+		{"NaN", DefaultReviewReputationThreshold},
+		{"Inf", DefaultReviewReputationThreshold},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			t.Setenv("REVIEW_REPUTATION_THRESHOLD", tt.raw)
+			initScoring()
+			if ReviewReputationThreshold != tt.want {
+				t.Errorf("ReviewReputationThreshold = %g, want %g", ReviewReputationThreshold, tt.want)
+			}
+		})
+	}
+}
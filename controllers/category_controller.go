@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"CROWD_MARKET/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryRequest struct {
+	Slug   string `json:"slug" binding:"required"`
+	Parent string `json:"parent"`
+	Name   string `json:"name" binding:"required"`
+	Icon   string `json:"icon"`
+}
+
+type CategoryUpdateRequest struct {
+	Parent *string `json:"parent"`
+	Name   *string `json:"name"`
+	Icon   *string `json:"icon"`
+}
+
+// 🗂️ The category tree
+func ListCategories(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Categories fetched successfully",
+		"categories": services.GetCategoryTree(),
+	})
+}
+
+// 🗂️ Add a category, optionally under a parent
+func CreateCategory(c *gin.Context) {
+	var request CategoryRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := services.CreateCategory(request.Slug, request.Parent, request.Name, request.Icon)
+	if err != nil {
+		respondListingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Category created successfully",
+		"category": category,
+	})
+}
+
+// ✏️ Rename or move a category
+func UpdateCategory(c *gin.Context) {
+	var request CategoryUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := services.UpdateCategory(c.Param("slug"), services.CategoryUpdate{
+		Name:   request.Name,
+		Icon:   request.Icon,
+		Parent: request.Parent,
+	})
+	if errors.Is(err, services.ErrUnknownCategory) && !isListingQueryError(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+	if err != nil {
+		respondListingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Category updated successfully",
+		"category": category,
+	})
+}
+
+// 🗑️ Delete an unused leaf category
+func DeleteCategory(c *gin.Context) {
+	err := services.DeleteCategory(c.Param("slug"))
+	switch {
+	case errors.Is(err, services.ErrUnknownCategory):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	case errors.Is(err, services.ErrCategoryInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"CROWD_MARKET/services"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExchangeRateRequest struct {
+	Base          string  `json:"base" binding:"required"`
+	Quote         string  `json:"quote" binding:"required"`
+	Rate          float64 `json:"rate" binding:"required,gt=0"`
+	EffectiveDate string  `json:"effective_date" binding:"required"`
+	Source        string  `json:"source"`
+}
+
+type ExchangeRateUpdateRequest struct {
+	Rate   float64 `json:"rate" binding:"required,gt=0"`
+	Source string  `json:"source"`
+}
+
+// 💱 List exchange rates, optionally for one currency
+func ListExchangeRates(c *gin.Context) {
+	rates, err := services.ListExchangeRates(c.Query("currency"))
+	if err != nil {
+		respondListingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Exchange rates fetched successfully",
+		"rates":   rates,
+	})
+}
+
+// 💱 Add a dated rate (replaces the rate for the same pair and day)
+func CreateExchangeRate(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var request ExchangeRateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rate, err := services.SaveExchangeRate(services.ExchangeRateInput{
+		Base:          request.Base,
+		Quote:         request.Quote,
+		Rate:          request.Rate,
+		EffectiveDate: request.EffectiveDate,
+		Source:        request.Source,
+	}, userID.Hex())
+	if err != nil {
+		respondListingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Exchange rate saved successfully",
+		"rate":    rate,
+	})
+}
+
+// 💱 Correct an existing rate
+func UpdateExchangeRate(c *gin.Context) {
+	var request ExchangeRateUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rate, err := services.UpdateExchangeRate(c.Param("id"), request.Rate, request.Source)
+	if errors.Is(err, services.ErrRateNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondListingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Exchange rate updated successfully",
+		"rate":    rate,
+	})
+}
+
+// 🗑️ Delete a rate
+func DeleteExchangeRate(c *gin.Context) {
+	err := services.DeleteExchangeRate(c.Param("id"))
+	if errors.Is(err, services.ErrRateNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Exchange rate deleted successfully"})
+}
+
+// 📥 Import rates from a CSV upload ("file" field) or a text/csv body
+func ImportExchangeRates(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	body := c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is required"})
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	report, err := services.ImportExchangeRatesCSV(body, userID.Hex())
+	if err != nil {
+		respondListingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Exchange rates imported",
+		"import":  report,
+	})
+}
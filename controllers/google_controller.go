@@ -3,6 +3,7 @@ package controllers
 import (
 	"CROWD_MARKET/config"
 	"CROWD_MARKET/services"
+	"CROWD_MARKET/utils"
 	"context"
 	"encoding/json"
 	"net/http"
@@ -56,10 +57,14 @@ func GoogleCallBack(c *gin.Context) {
 
 	user, _ := services.FindUserByEmail(email)
 	if user == nil {
-		services.CreateGoogleUser(name, email)
+		user, err = services.CreateGoogleUser(name, email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
+			return
+		}
 	}
 
-	tokenString, _ := services.GenerateJWT(email)
+	tokenString, _ := utils.GenerateJWT(user.ID.Hex(), email, user.Role)
 	c.JSON(http.StatusOK, gin.H{
 		"token": tokenString,
 		"user": gin.H{
@@ -114,11 +119,15 @@ func GoogleLoginWeb(c *gin.Context) {
 	// ✅ Create or find user
 	user, _ := services.FindUserByEmail(email)
 	if user == nil {
-		services.CreateGoogleUser(name, email)
+		user, err = services.CreateGoogleUser(name, email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
+			return
+		}
 	}
 
 	// ✅ Generate your app's JWT token
-	token, err := services.GenerateJWT(email)
+	token, err := utils.GenerateJWT(user.ID.Hex(), email, user.Role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate JWT"})
 		return
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// importParam reads an import option from the multipart form or the query
+func importParam(c *gin.Context, key string) string {
+	if value := c.PostForm(key); value != "" {
+		return value
+	}
+	return c.Query(key)
+}
+
+// importFormat takes an explicit format, else guesses from the content type
+// or the uploaded file name, defaulting to CSV
+func importFormat(c *gin.Context, filename string) string {
+	if format := strings.ToLower(importParam(c, "format")); format != "" {
+		return format
+	}
+	switch c.ContentType() {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return services.ImportNDJSON
+	}
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".ndjson", ".jsonl":
+		return services.ImportNDJSON
+	}
+	return services.ImportCSV
+}
+
+// 📥 Bulk import price reports from CSV or NDJSON, sent as the multipart
+// "file" or as the request body. Options: format (csv|ndjson), mapping (JSON
+// object of product field to column name), currency and dry_run.
+func ImportProducts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	if err := services.EnsureCanContribute(userID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.MaxImportBytes)
+	body, filename := c.Request.Body, ""
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Import file is required"})
+			return
+		}
+		defer file.Close()
+		body, filename = file, header.Filename
+	}
+
+	opts := services.ImportOptions{
+		Format:   importFormat(c, filename),
+		Currency: importParam(c, "currency"),
+	}
+	if raw := importParam(c, "mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &opts.Mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "mapping must be a JSON object of field to column name"})
+			return
+		}
+	}
+	if raw := importParam(c, "dry_run"); raw != "" {
+		dryRun, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run value"})
+			return
+		}
+		opts.DryRun = dryRun
+	}
+
+	report, err := services.ImportProducts(body, userID, opts)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import file is too large"})
+		return
+	}
+	if err != nil {
+		if report != nil {
+			// Some batches were saved before the failure
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "import": report})
+			return
+		}
+		respondListingError(c, err)
+		return
+	}
+
+	message := "Import finished"
+	if opts.DryRun {
+		message = "Import validated, nothing was saved"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"import":  report,
+	})
+}
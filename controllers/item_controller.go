@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"CROWD_MARKET/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 📚 List catalog items
+func ListItems(c *gin.Context) {
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := services.ListItems(c.Query("category"), c.Query("q"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Items fetched successfully",
+		"items":   items,
+	})
+}
+
+// 📚 Get a catalog item by ID
+func GetItemByID(c *gin.Context) {
+	item, err := services.GetItemByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item fetched successfully",
+		"item":    item,
+	})
+}
+
+// 🧾 Latest price reports for an item across reporters
+func GetItemReports(c *gin.Context) {
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reports, err := services.GetItemReports(c.Param("id"), c.Query("area"), limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Price reports fetched successfully",
+		"reports": reports,
+	})
+}
+
+// 📊 Going-rate statistics for an item
+func GetItemStats(c *gin.Context) {
+	if _, err := services.GetItemByID(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+
+	stats, err := services.GetItemPriceStats(c.Param("id"), c.Query("area"), c.Query("window"), c.Query("currency"))
+	if err != nil {
+		if isListingQueryError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Price statistics fetched successfully",
+		"stats":   stats,
+	})
+}
+
+// 📈 Median price trend for an item
+func GetItemHistory(c *gin.Context) {
+	if _, err := services.GetItemByID(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+
+	history, err := services.GetItemPriceHistory(c.Param("id"), c.Query("area"), c.Query("bucket"), c.Query("window"), c.Query("currency"))
+	if err != nil {
+		if isListingQueryError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Price history fetched successfully",
+		"history": history,
+	})
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"CROWD_MARKET/services"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LocationRequest struct {
+	Name     string   `json:"name" binding:"required"`
+	Type     string   `json:"type" binding:"required"`
+	ParentID string   `json:"parent_id"`
+	Aliases  []string `json:"aliases"`
+}
+
+// 🌍 Browse the hierarchy: countries, or the children of ?parent_id=
+func ListLocations(c *gin.Context) {
+	locations, err := services.ListChildLocations(c.Query("parent_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Locations fetched successfully",
+		"locations": locations,
+	})
+}
+
+// 🌍 One location with its full path
+func GetLocation(c *gin.Context) {
+	location, err := services.GetLocation(c.Param("id"))
+	if errors.Is(err, services.ErrLocationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Location fetched successfully",
+		"location": location,
+	})
+}
+
+// 🔎 Location autocomplete: ?q=yab&type=area,market&within=<id>
+func AutocompleteLocations(c *gin.Context) {
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var types []string
+	for _, raw := range c.QueryArray("type") {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+	}
+
+	locations, err := services.AutocompleteLocations(c.Query("q"), types, c.Query("within"), limit)
+	if err != nil {
+		respondListingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Locations fetched successfully",
+		"locations": locations,
+	})
+}
+
+// 🌍 Add a country, state, city, area or market
+func CreateLocation(c *gin.Context) {
+	var request LocationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	location, err := services.CreateLocation(services.LocationInput{
+		Name:     request.Name,
+		Type:     request.Type,
+		ParentID: request.ParentID,
+		Aliases:  request.Aliases,
+	})
+	if err != nil {
+		respondListingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Location created successfully",
+		"location": location,
+	})
+}
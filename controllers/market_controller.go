@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"CROWD_MARKET/model"
+	"CROWD_MARKET/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MarketRequest struct {
+	Name       string               `json:"name" binding:"required"`
+	Type       string               `json:"type" binding:"required"`
+	Address    string               `json:"address"`
+	LocationID string               `json:"location_id"`
+	Lat        *float64             `json:"lat"`
+	Lng        *float64             `json:"lng"`
+	Hours      []model.OpeningHours `json:"hours"`
+	Timezone   string               `json:"timezone"`
+}
+
+func (r MarketRequest) input() services.MarketInput {
+	return services.MarketInput{
+		Name:       r.Name,
+		Type:       r.Type,
+		Address:    r.Address,
+		LocationID: r.LocationID,
+		Lat:        r.Lat,
+		Lng:        r.Lng,
+		Hours:      r.Hours,
+		Timezone:   r.Timezone,
+	}
+}
+
+// 🏪 Markets, filtered by ?location_id=, ?type= and ?open_now=true
+func ListMarkets(c *gin.Context) {
+	var locationID primitive.ObjectID
+	if raw := c.Query("location_id"); raw != "" {
+		id, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location_id"})
+			return
+		}
+		locationID = id
+	}
+	if marketType := c.Query("type"); marketType != "" && !model.MarketTypes[marketType] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be open_market, supermarket or roadside"})
+		return
+	}
+	openNow, err := parseOptionalBool(c, "open_now")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Markets fetched successfully",
+		"markets": services.ListMarkets(locationID, c.Query("type"), openNow),
+	})
+}
+
+// 🏪 One market with its opening hours and whether it is open now
+func GetMarket(c *gin.Context) {
+	market, err := services.GetMarket(c.Param("id"))
+	if err != nil {
+		respondMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Market fetched successfully",
+		"market":  market,
+	})
+}
+
+// 🏷️ Latest price of every item reported at a market
+func GetMarketPrices(c *gin.Context) {
+	prices, err := services.GetMarketLatestPrices(c.Param("id"))
+	if err != nil {
+		respondMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Market prices fetched successfully",
+		"prices":  prices,
+	})
+}
+
+// 🏪 Add a market
+func CreateMarket(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	var request MarketRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	market, err := services.CreateMarket(request.input(), userID)
+	if err != nil {
+		respondListingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Market created successfully",
+		"market":  market,
+	})
+}
+
+// ✏️ Replace a market's details and opening hours
+func UpdateMarket(c *gin.Context) {
+	var request MarketRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	market, err := services.UpdateMarket(c.Param("id"), request.input())
+	if err != nil {
+		respondMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Market updated successfully",
+		"market":  market,
+	})
+}
+
+func respondMarketError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMarketNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case isListingQueryError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
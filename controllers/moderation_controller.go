@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"CROWD_MARKET/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FlagRequest struct {
+	Reason string `json:"reason" binding:"required"`
+	Note   string `json:"note"`
+}
+
+type ModerationActionRequest struct {
+	Action string `json:"action" binding:"required"`
+	Note   string `json:"note" binding:"required"`
+	// Only used by "suspend"; 0 suspends until reinstated
+	Days int `json:"days" binding:"min=0"`
+}
+
+// respondModerationError maps moderation and flagging errors: bad input is
+// 400, an unknown product or user 404, and anything else a server error
+func respondModerationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidFlagReason), errors.Is(err, services.ErrInvalidModAction):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadyFlagged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSuspended):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidID), errors.Is(err, services.ErrProductNotFound), errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// 🚩 Report a fake or offensive listing
+func FlagProduct(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var request FlagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	flag, err := services.FlagProduct(c.Param("id"), userID.Hex(), request.Reason, request.Note)
+	if err != nil {
+		respondModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Report submitted successfully",
+		"report":  flag,
+	})
+}
+
+// 🗂️ Open user reports grouped by product
+func GetModerationQueue(c *gin.Context) {
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	queue, err := services.GetModerationQueue(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Moderation queue fetched successfully",
+		"queue":   queue,
+	})
+}
+
+// 🕵️ Price reports held for review or flagged as suspect
+func GetReviewQueue(c *gin.Context) {
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	products, err := services.GetReviewQueue(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Review queue fetched successfully",
+		"products": products,
+	})
+}
+
+// 🛡️ Hide, restore, approve, delete or dismiss reports on a product
+func ModerateProduct(c *gin.Context) {
+	moderatorID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var request ModerationActionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := services.ModerateProduct(c.Param("id"), moderatorID.Hex(), request.Action, request.Note)
+	if err != nil {
+		respondModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Moderation action applied successfully",
+		"product": product,
+	})
+}
+
+// 🛡️ Warn, suspend or reinstate a product author
+func ModerateUser(c *gin.Context) {
+	moderatorID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var request ModerationActionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := services.ModerateUser(c.Param("id"), moderatorID.Hex(), request.Action, request.Note, request.Days)
+	if err != nil {
+		respondModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Moderation action applied successfully",
+		"user":    profile,
+	})
+}
+
+// 📜 Moderation log
+func ListModerationActions(c *gin.Context) {
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actions, err := services.ListModerationActions(c.Query("product_id"), c.Query("user_id"), limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Moderation actions fetched successfully",
+		"actions": actions,
+	})
+}
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"CROWD_MARKET/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRespondModerationError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{services.ErrInvalidModAction, http.StatusBadRequest},
+		{services.ErrInvalidFlagReason, http.StatusBadRequest},
+		{services.ErrAlreadyFlagged, http.StatusConflict},
+		{services.ErrSuspended, http.StatusForbidden},
+		{services.ErrInvalidID, http.StatusNotFound},
+		{services.ErrProductNotFound, http.StatusNotFound},
+		{fmt.Errorf("moderating: %w", services.ErrUserNotFound), http.StatusNotFound},
+		{errors.New("server selection timeout"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			respondModerationError(c, tt.err)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package controllers
 
 import (
+	"CROWD_MARKET/config"
 	"CROWD_MARKET/model"
 	"CROWD_MARKET/services"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -11,70 +14,317 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// 🔐 Resolve the authenticated caller set by the auth middleware
+func currentUserID(c *gin.Context) (primitive.ObjectID, bool) {
+	userID, err := primitive.ObjectIDFromHex(c.GetString("user_id"))
+	if err != nil {
+		return primitive.NilObjectID, false
+	}
+	return userID, true
+}
+
+// 🔐 Moderators and admins can see hidden listings and act on any product
+func isModerator(c *gin.Context) bool {
+	role := c.GetString("role")
+	return role == model.RoleModerator || role == model.RoleAdmin
+}
+
 // ✅ Add new product
 func AddProduct(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	if err := services.EnsureCanContribute(userID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
 	name := c.PostForm("name")
 	priceStr := c.PostForm("price")
 	area := c.PostForm("area")
 	description := c.PostForm("description")
 	category := c.PostForm("category")
 
-	if name == "" || priceStr == "" || area == "" || description == "" {
+	// A location ID can stand in for the free-text area
+	var locationID *primitive.ObjectID
+	if raw := c.PostForm("location_id"); raw != "" {
+		id, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location_id"})
+			return
+		}
+		locationID = &id
+	}
+	var marketID *primitive.ObjectID
+	if raw := c.PostForm("market_id"); raw != "" {
+		id, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid market_id"})
+			return
+		}
+		marketID = &id
+	}
+
+	if name == "" || priceStr == "" || (area == "" && locationID == nil) || description == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "All fields are required"})
 		return
 	}
+	if err := validateCategory(category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	price, err := strconv.ParseFloat(priceStr, 64)
+	price, err := parsePrice(priceStr, c.PostForm("currency"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price value"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price value: " + err.Error()})
 		return
 	}
 
-	file, fileHeader, err := c.Request.FormFile("image")
+	quantity, unit, err := parseQuantity(c.PostForm("quantity"), c.PostForm("unit"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Image file is required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	defer file.Close()
 
-	imageURL, err := services.UploadToCloudinary(file, fileHeader)
+	location, err := parseLatLng(c.PostForm("lat"), c.PostForm("lng"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload image: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	userIDStr, _ := c.Get("user_id")
-	userID, _ := primitive.ObjectIDFromHex(userIDStr.(string))
+	// Images come as form files, as direct uploads from POST /uploads/sign,
+	// or both
+	headers := imageHeaders(c)
+	uploadIDs := c.PostFormArray("upload_id")
+	if len(headers)+len(uploadIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Image file or upload_id is required"})
+		return
+	}
+	if len(headers)+len(uploadIDs) > config.MaxProductImages {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d images are allowed", config.MaxProductImages)})
+		return
+	}
+
+	stored, err := services.CollectImages(userID, headers, uploadIDs)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+	images := services.NewProductImages(stored)
 
 	now := time.Now()
 	product := model.Product{
 		UserID:      userID,
 		Name:        name,
 		Price:       price,
+		Quantity:    quantity,
+		Unit:        unit,
 		Area:        area,
+		LocationID:  locationID,
+		MarketID:    marketID,
 		Description: description,
-		ImageURL:    imageURL,
+		ImageURL:    images[0].URL,
+		Images:      images,
 		Category:    category,
+		Location:    location,
 		CreatedAt:   &now,
 	}
 
 	savedProduct, err := services.AddProduct(product)
+	if err != nil {
+		// The uploads belong to no product after all
+		services.DeleteUnusedImages(services.ProductImageURLs(product))
+	}
+	if errors.Is(err, services.ErrLocationNotFound) || errors.Is(err, services.ErrMarketNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	response := gin.H{
 		"message": "Product added successfully",
 		"product": savedProduct,
-	})
+	}
+	if warning := services.PriceWarning(savedProduct); warning != "" {
+		response["warning"] = warning
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// ✅ Public marketplace listing
+func ListProducts(c *gin.Context) {
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts := services.ProductListOptions{
+		Filter: filter,
+		Sort:   c.Query("sort"),
+		Cursor: c.Query("cursor"),
+	}
+
+	if opts.Limit, err = parseLimit(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := services.ListProducts(opts)
+	if err != nil {
+		if isListingQueryError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	products := make([]*model.Product, len(page.Products))
+	for i := range page.Products {
+		products[i] = &page.Products[i]
+	}
+	conversion, err := convertListing(c, products)
+	if err != nil {
+		respondListingError(c, err)
+		return
+	}
+
+	response := gin.H{
+		"message":     "Products fetched successfully",
+		"products":    page.Products,
+		"next_cursor": page.NextCursor,
+	}
+	for key, value := range conversion {
+		response[key] = value
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// 🔍 Full-text search over name, description and category
+func SearchProducts(c *gin.Context) {
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := services.SearchQuery{
+		Text:   c.Query("q"),
+		Filter: filter,
+		Cursor: c.Query("cursor"),
+	}
+	if query.Limit, err = parseLimit(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := services.ProductSearch.Search(c.Request.Context(), query)
+	if err != nil {
+		if isListingQueryError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	products := make([]*model.Product, len(page.Hits))
+	for i := range page.Hits {
+		products[i] = &page.Hits[i].Product
+	}
+	conversion, err := convertListing(c, products)
+	if err != nil {
+		respondListingError(c, err)
+		return
+	}
+
+	response := gin.H{
+		"message":     "Search completed successfully",
+		"results":     page.Hits,
+		"next_cursor": page.NextCursor,
+	}
+	for key, value := range conversion {
+		response[key] = value
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// 📍 Reports near a point, nearest first
+func GetNearbyProducts(c *gin.Context) {
+	location, err := parseLatLng(c.Query("lat"), c.Query("lng"))
+	if err != nil || location == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Valid lat and lng are required"})
+		return
+	}
+
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := services.NearbyQuery{
+		Lat:    location.Coordinates[1],
+		Lng:    location.Coordinates[0],
+		Filter: filter,
+	}
+	if radiusStr := c.Query("radius_km"); radiusStr != "" {
+		query.RadiusKm, err = parseFiniteFloat(radiusStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid radius_km value"})
+			return
+		}
+	}
+	if query.Limit, err = parseLimit(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := services.FindNearbyProducts(query)
+	if err != nil {
+		if isListingQueryError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	products := make([]*model.Product, len(results))
+	for i := range results {
+		products[i] = &results[i].Product
+	}
+	conversion, err := convertListing(c, products)
+	if err != nil {
+		respondListingError(c, err)
+		return
+	}
+
+	response := gin.H{
+		"message":  "Nearby products fetched successfully",
+		"products": results,
+	}
+	for key, value := range conversion {
+		response[key] = value
+	}
+	c.JSON(http.StatusOK, response)
 }
 
-// ✅ Get all products
+// ✅ Get all products owned by the caller
 func GetAllProducts(c *gin.Context) {
-	userID := c.GetString("user_id")
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
 
-	products, err := services.GetAllProducts(userID)
+	products, err := services.GetAllProducts(userID.Hex())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -96,24 +346,191 @@ func GetProductByID(c *gin.Context) {
 		return
 	}
 
+	if !canView(c, product) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Product fetched successfully",
 		"product": product,
 	})
 }
 
+// Hidden listings are only visible to their owner and moderators
+func canView(c *gin.Context, product *model.Product) bool {
+	if !product.Hidden || isModerator(c) {
+		return true
+	}
+	userID, ok := currentUserID(c)
+	return ok && userID == product.UserID
+}
+
+// 📜 Revision history of a product, newest first
+func GetProductHistory(c *gin.Context) {
+	productID := c.Param("id")
+
+	product, err := services.GetProductByID(productID)
+	if err != nil || !canView(c, product) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revisions, err := services.GetProductHistory(product, limit, isModerator(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Product history fetched successfully",
+		"revisions": revisions,
+	})
+}
+
+// normalizeJSONQuantity validates a JSON "quantity" and resolves "unit" to
+// its registry code
+func normalizeJSONQuantity(fields map[string]interface{}) error {
+	if raw, ok := fields["quantity"]; ok {
+		quantity, isNumber := raw.(float64)
+		if !isNumber {
+			return services.ErrInvalidQuantity
+		}
+		if err := services.ValidateQuantity(quantity); err != nil {
+			return err
+		}
+	}
+	if raw, ok := fields["unit"]; ok {
+		text, _ := raw.(string)
+		unit, err := services.LookupUnit(text)
+		if err != nil {
+			return err
+		}
+		fields["unit"] = unit.Code
+	}
+	return nil
+}
+
+// normalizeJSONLocation turns a JSON "location", a GeoJSON point as the API
+// returns it, into a validated GeoPoint
+func normalizeJSONLocation(fields map[string]interface{}) error {
+	raw, ok := fields["location"]
+	if !ok {
+		return nil
+	}
+	invalid := errors.New(`location must be a GeoJSON point: {"type": "Point", "coordinates": [lng, lat]}`)
+	point, _ := raw.(map[string]interface{})
+	coordinates, _ := point["coordinates"].([]interface{})
+	if point == nil || (point["type"] != nil && point["type"] != "Point") || len(coordinates) != 2 {
+		return invalid
+	}
+	lng, lngOK := coordinates[0].(float64)
+	lat, latOK := coordinates[1].(float64)
+	if !lngOK || !latOK {
+		return invalid
+	}
+	if err := services.ValidateCoordinates(lat, lng); err != nil {
+		return err
+	}
+	fields["location"] = model.NewGeoPoint(lat, lng)
+	return nil
+}
+
+// normalizeJSONPrice turns a JSON "price" (a string such as "₦1,500" or a
+// plain number) and optional "currency" into the stored Money value
+func normalizeJSONPrice(fields map[string]interface{}) error {
+	currency, _ := fields["currency"].(string)
+	if _, ok := fields["currency"]; ok {
+		delete(fields, "currency")
+		if _, hasPrice := fields["price"]; !hasPrice {
+			return errors.New("currency can only be changed together with price")
+		}
+	}
+
+	raw, ok := fields["price"]
+	if !ok {
+		return nil
+	}
+	var text string
+	switch v := raw.(type) {
+	case string:
+		text = v
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return errors.New("price must be a string or a number")
+	}
+
+	price, err := parsePrice(text, currency)
+	if err != nil {
+		return err
+	}
+	fields["price"] = price
+	return nil
+}
+
+// respondProductWriteError answers a failed owner write: 404 for an unknown
+// product, 403 for someone else's, and 500 for anything else
+func respondProductWriteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidID), errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // ✅ Update product
 func UpdateProduct(c *gin.Context) {
 	productID := c.Param("id")
-	userID := c.GetString("user_id")
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	if err := services.EnsureCanContribute(userID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 
 	updateFields := make(map[string]interface{})
+	// New primary image uploaded with the form, if any
+	var newImage *services.StoredImage
 
 	if c.ContentType() == "application/json" {
 		if err := c.ShouldBindJSON(&updateFields); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
 			return
 		}
+		// Which fields are editable is up to UpdateProductByUser; these only
+		// turn JSON values into the stored types
+		if err := normalizeJSONPrice(updateFields); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price value: " + err.Error()})
+			return
+		}
+		if err := normalizeJSONQuantity(updateFields); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := normalizeJSONLocation(updateFields); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if raw, ok := updateFields["category"]; ok {
+			category, _ := raw.(string)
+			if err := validateCategory(category); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
 	} else {
 		name := c.PostForm("name")
 		priceStr := c.PostForm("price")
@@ -125,9 +542,9 @@ func UpdateProduct(c *gin.Context) {
 			updateFields["name"] = name
 		}
 		if priceStr != "" {
-			price, err := strconv.ParseFloat(priceStr, 64)
+			price, err := parsePrice(priceStr, c.PostForm("currency"))
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price value"})
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price value: " + err.Error()})
 				return
 			}
 			updateFields["price"] = price
@@ -135,51 +552,114 @@ func UpdateProduct(c *gin.Context) {
 		if area != "" {
 			updateFields["area"] = area
 		}
+		if locationID := c.PostForm("location_id"); locationID != "" {
+			updateFields["location_id"] = locationID
+		}
+		if marketID := c.PostForm("market_id"); marketID != "" {
+			updateFields["market_id"] = marketID
+		}
 		if description != "" {
 			updateFields["description"] = description
 		}
 		if category != "" {
+			if err := validateCategory(category); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 			updateFields["category"] = category
 		}
+		if quantityStr := c.PostForm("quantity"); quantityStr != "" {
+			quantity, err := services.ParseQuantity(quantityStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			updateFields["quantity"] = quantity
+		}
+		if unitStr := c.PostForm("unit"); unitStr != "" {
+			unit, err := services.LookupUnit(unitStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			updateFields["unit"] = unit.Code
+		}
+
+		location, err := parseLatLng(c.PostForm("lat"), c.PostForm("lng"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if location != nil {
+			updateFields["location"] = location
+		}
 
-		// 🧩 Optional new image
-		file, fileHeader, err := c.Request.FormFile("image")
+		// 🧩 Optional new image, replacing the primary image
+		file, _, err := c.Request.FormFile("image")
 		if err == nil {
 			defer file.Close()
-			oldProduct, err := services.GetProductByID(productID)
-			if err == nil && oldProduct.ImageURL != "" {
-				_ = services.DeleteFromCloudinary(oldProduct.ImageURL)
-			}
-			imageURL, err := services.UploadToCloudinary(file, fileHeader)
+			newImage, err = services.UploadImage(file)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload new image: " + err.Error()})
+				respondUploadError(c, err)
 				return
 			}
-			updateFields["image_url"] = imageURL
 		}
 	}
 
-	if len(updateFields) == 0 {
+	if len(updateFields) == 0 && newImage == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
 		return
 	}
 
-	updatedProduct, err := services.UpdateProductByUser(productID, userID, updateFields)
+	var updatedProduct *model.Product
+	var err error
+	if len(updateFields) > 0 {
+		updatedProduct, err = services.UpdateProductByUser(productID, userID.Hex(), updateFields)
+	}
+	// The image goes through the same checked write as the image endpoints.
+	// The old one is deleted once replaced, the new one if the update failed.
+	if err == nil && newImage != nil {
+		var replaced string
+		updatedProduct, replaced, err = services.ReplacePrimaryImage(productID, userID.Hex(), *newImage)
+		if err == nil {
+			services.DeleteUnusedImages([]string{replaced})
+		}
+	}
+	if err != nil && newImage != nil {
+		services.DeleteUnusedImages([]string{newImage.URL})
+	}
+	if errors.Is(err, services.ErrImagesChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrLocationNotFound) || errors.Is(err, services.ErrMarketNotFound) ||
+		errors.Is(err, services.ErrFieldNotEditable) || isListingQueryError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		respondProductWriteError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"message": "Product updated successfully",
 		"product": updatedProduct,
-	})
+	}
+	if warning := services.PriceWarning(*updatedProduct); warning != "" {
+		response["warning"] = warning
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // ✅ Delete product
 func DeleteProduct(c *gin.Context) {
 	productID := c.Param("id")
-	userID := c.GetString("user_id")
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
 
 	product, err := services.GetProductByID(productID)
 	if err != nil {
@@ -187,20 +667,64 @@ func DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	if product.UserID.Hex() != userID {
+	if product.UserID != userID {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to delete this product"})
 		return
 	}
 
-	if product.ImageURL != "" {
-		_ = services.DeleteFromCloudinary(product.ImageURL)
+	// Soft delete; the image is removed when the product is purged
+	err = services.DeleteProductByUser(productID, userID.Hex())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Product deleted successfully",
+		"restore_until": time.Now().Add(config.ProductRestoreWindow),
+	})
+}
+
+// ♻️ Restore a product the caller deleted
+func RestoreProduct(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	product, err := services.RestoreProduct(c.Param("id"), userID.Hex())
+	if errors.Is(err, services.ErrNotRestorable) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Product restored successfully",
+		"product": product,
+	})
+}
+
+// 🗑️ List the caller's deleted products that can still be restored
+func GetDeletedProducts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
 	}
 
-	err = services.DeleteProduct(productID)
+	products, err := services.GetDeletedProducts(userID.Hex())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Product deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Deleted products fetched successfully",
+		"products": products,
+	})
 }
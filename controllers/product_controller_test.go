@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"CROWD_MARKET/model"
+	"CROWD_MARKET/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNormalizeJSONLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		location interface{}
+		want     *model.GeoPoint
+		wantErr  bool
+	}{
+		{"geojson point", map[string]interface{}{"type": "Point", "coordinates": []interface{}{3.3792, 6.5244}}, model.NewGeoPoint(6.5244, 3.3792), false},
+		{"type omitted", map[string]interface{}{"coordinates": []interface{}{3.3792, 6.5244}}, model.NewGeoPoint(6.5244, 3.3792), false},
+		{"other geometry", map[string]interface{}{"type": "Polygon", "coordinates": []interface{}{3.3, 6.5}}, nil, true},
+		{"one coordinate", map[string]interface{}{"coordinates": []interface{}{3.3}}, nil, true},
+		{"text coordinates", map[string]interface{}{"coordinates": []interface{}{"3.3", "6.5"}}, nil, true},
+		{"latitude out of range", map[string]interface{}{"coordinates": []interface{}{3.3, 95.0}}, nil, true},
+		{"not an object", "6.5,3.3", nil, true},
+		{"null", nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := map[string]interface{}{"location": tt.location}
+			err := normalizeJSONLocation(fields)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got, ok := fields["location"].(*model.GeoPoint)
+			if !ok || got.Type != tt.want.Type || got.Coordinates[0] != tt.want.Coordinates[0] || got.Coordinates[1] != tt.want.Coordinates[1] {
+				t.Errorf("location = %#v, want %#v", fields["location"], tt.want)
+			}
+		})
+	}
+
+	fields := map[string]interface{}{"name": "Rice"}
+	if err := normalizeJSONLocation(fields); err != nil || len(fields) != 1 {
+		t.Errorf("update without location changed to %v, %v", fields, err)
+	}
+}
+
+func TestNormalizeJSONPriceConsumesCurrency(t *testing.T) {
+	fields := map[string]interface{}{"price": "1500", "currency": "ghs"}
+	if err := normalizeJSONPrice(fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["currency"]; ok {
+		t.Error("currency was left for the service, which does not store it")
+	}
+	if price, ok := fields["price"].(model.Money); !ok || price.Currency != "GHS" || price.Amount != 150000 {
+		t.Errorf("price = %#v", fields["price"])
+	}
+
+	if err := normalizeJSONPrice(map[string]interface{}{"currency": "GHS"}); err == nil {
+		t.Error("currency without a price was accepted")
+	}
+}
+
+func TestRespondProductWriteError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{services.ErrInvalidID, http.StatusNotFound},
+		{services.ErrProductNotFound, http.StatusNotFound},
+		{services.ErrNotOwner, http.StatusForbidden},
+		{fmt.Errorf("updating: %w", services.ErrNotOwner), http.StatusForbidden},
+		{errors.New("server selection timeout"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			respondProductWriteError(c, tt.err)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRespondImageError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{services.ErrTooManyImages, http.StatusBadRequest},
+		{services.ErrLastProductImage, http.StatusBadRequest},
+		{services.ErrProductNotFound, http.StatusNotFound},
+		{services.ErrNotOwner, http.StatusForbidden},
+		{errors.New("server selection timeout"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			respondImageError(c, tt.err)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
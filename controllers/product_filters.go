@@ -0,0 +1,259 @@
+package controllers
+
+import (
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+	"CROWD_MARKET/services"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 🔎 Parse listing filters from the query string.
+// category may repeat (?category=a&category=b) or be comma separated.
+// ?currency= is the caller's currency, as on the item stats and history:
+// results are converted into it (see convertListing) and bare min_price and
+// max_price are read in it. Price bounds only match listings priced in the
+// bounds' currency.
+func parseProductFilter(c *gin.Context) (services.ProductFilter, error) {
+	var filter services.ProductFilter
+
+	for _, raw := range c.QueryArray("category") {
+		for _, category := range strings.Split(raw, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				filter.Categories = append(filter.Categories, category)
+			}
+		}
+	}
+
+	filter.Area = strings.TrimSpace(c.Query("area"))
+
+	var err error
+	if raw := c.Query("location_id"); raw != "" {
+		if filter.LocationID, err = primitive.ObjectIDFromHex(raw); err != nil {
+			return filter, errors.New("invalid location_id")
+		}
+	}
+	var currency string
+	if raw := c.Query("currency"); raw != "" {
+		if currency, err = model.NormalizeCurrency(raw); err != nil {
+			return filter, err
+		}
+	}
+	if filter.MinPrice, err = parseOptionalPrice(c, "min_price", currency); err != nil {
+		return filter, err
+	}
+	if filter.MaxPrice, err = parseOptionalPrice(c, "max_price", currency); err != nil {
+		return filter, err
+	}
+	if per := c.Query("per"); per != "" {
+		if filter.UnitBasis, err = services.UnitBasis(per); err != nil {
+			return filter, err
+		}
+	}
+	// The bounds decide which listings can be compared; a symbol ("₦5,000")
+	// picks the currency itself
+	for _, bound := range []*model.Money{filter.MinPrice, filter.MaxPrice} {
+		if bound != nil && filter.Currency == "" {
+			filter.Currency = bound.Currency
+		}
+	}
+	if filter.MinConfidence, err = parseOptionalFloat(c, "min_confidence"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedAfter, err = parseOptionalTime(c, "created_after"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedBefore, err = parseOptionalTime(c, "created_before"); err != nil {
+		return filter, err
+	}
+
+	if market := c.Query("market_id"); market != "" {
+		if filter.MarketID, err = primitive.ObjectIDFromHex(market); err != nil {
+			return filter, errors.New("invalid market_id")
+		}
+	}
+	if filter.OpenNow, err = parseOptionalBool(c, "open_now"); err != nil {
+		return filter, err
+	}
+
+	if seller := c.Query("seller"); seller != "" {
+		sellerID, err := primitive.ObjectIDFromHex(seller)
+		if err != nil {
+			return filter, errors.New("invalid seller ID")
+		}
+		filter.SellerID = sellerID
+	}
+
+	return filter, filter.Validate()
+}
+
+func parseOptionalBool(c *gin.Context, key string) (bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s value", key)
+	}
+	return value, nil
+}
+
+// parseFiniteFloat parses a number, rejecting NaN and infinities that
+// strconv accepts but no range check catches
+func parseFiniteFloat(raw string) (float64, error) {
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, strconv.ErrSyntax
+	}
+	return value, nil
+}
+
+func parseOptionalFloat(c *gin.Context, key string) (*float64, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := parseFiniteFloat(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value", key)
+	}
+	return &value, nil
+}
+
+// 📏 Parse an optional quantity and unit. The unit may be any registered
+// code or alias and is returned as its code; quantity defaults to 1.
+func parseQuantity(quantityStr, unitStr string) (float64, string, error) {
+	quantityStr, unitStr = strings.TrimSpace(quantityStr), strings.TrimSpace(unitStr)
+	if quantityStr == "" && unitStr == "" {
+		return 0, "", nil
+	}
+	if unitStr == "" {
+		return 0, "", errors.New("unit is required with quantity")
+	}
+	unit, err := services.LookupUnit(unitStr)
+	if err != nil {
+		return 0, "", err
+	}
+	if quantityStr == "" {
+		return 1, unit.Code, nil
+	}
+	quantity, err := services.ParseQuantity(quantityStr)
+	if err != nil {
+		return 0, "", err
+	}
+	return quantity, unit.Code, nil
+}
+
+// 💱 Convert listed prices into ?currency= at each report's date. Returns
+// the response fields describing the conversion, or nil when no currency
+// was requested.
+func convertListing(c *gin.Context, products []*model.Product) (gin.H, error) {
+	currency := c.Query("currency")
+	if currency == "" {
+		return nil, nil
+	}
+	cv, err := services.NewCurrencyConverter(c.Request.Context(), currency)
+	if err != nil {
+		return nil, err
+	}
+	cv.ConvertProducts(products)
+	return gin.H{"currency": cv.Target, "rates_used": cv.RatesUsed()}, nil
+}
+
+// 💰 Parse a price such as "₦1,500.50" or "1500". The currency comes from
+// the input itself, then from currency, then from the configured default.
+func parsePrice(raw, currency string) (model.Money, error) {
+	price, err := model.ParseMoney(raw, currency)
+	if errors.Is(err, model.ErrMissingCurrency) {
+		price, err = model.ParseMoney(raw, config.DefaultCurrency)
+	}
+	return price, err
+}
+
+func parseOptionalPrice(c *gin.Context, key, currency string) (*model.Money, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	price, err := parsePrice(raw, currency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", key, err)
+	}
+	return &price, nil
+}
+
+// Accepts RFC3339 timestamps or plain dates (YYYY-MM-DD, UTC midnight)
+func parseOptionalTime(c *gin.Context, key string) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid %s value, expected RFC3339 or YYYY-MM-DD", key)
+}
+
+// 📍 Optional lat/lng pair; both must be present or both absent
+func parseLatLng(latStr, lngStr string) (*model.GeoPoint, error) {
+	if latStr == "" && lngStr == "" {
+		return nil, nil
+	}
+	if latStr == "" || lngStr == "" {
+		return nil, errors.New("lat and lng must be provided together")
+	}
+	lat, err := parseFiniteFloat(latStr)
+	if err != nil {
+		return nil, errors.New("invalid lat value")
+	}
+	lng, err := parseFiniteFloat(lngStr)
+	if err != nil {
+		return nil, errors.New("invalid lng value")
+	}
+	if err := services.ValidateCoordinates(lat, lng); err != nil {
+		return nil, err
+	}
+	return model.NewGeoPoint(lat, lng), nil
+}
+
+// parseLimit reads ?limit=; zero means "use the default page size"
+func parseLimit(c *gin.Context) (int, error) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		return 0, errors.New("Invalid limit value")
+	}
+	return limit, nil
+}
+
+// respondListingError answers 400 for bad listing input and 500 otherwise
+func respondListingError(c *gin.Context, err error) {
+	if isListingQueryError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// isListingQueryError reports whether a listing error was caused by the request
+func isListingQueryError(err error) bool {
+	var filterErr *services.FilterError
+	return errors.Is(err, services.ErrInvalidSort) ||
+		errors.Is(err, services.ErrEmptySearch) ||
+		errors.Is(err, services.ErrInvalidCursor) ||
+		errors.As(err, &filterErr)
+}
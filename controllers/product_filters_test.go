@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseLatLng(t *testing.T) {
+	tests := []struct {
+		lat, lng string
+		wantNil  bool
+		wantErr  bool
+	}{
+		{"", "", true, false},
+		{"6.5244", "3.3792", false, false},
+		{"6.5", "", true, true},
+		{"abc", "3.3", true, true},
+		{"NaN", "3.3", true, true},
+		{"6.5", "nan", true, true},
+		{"Inf", "3.3", true, true},
+		{"6.5", "-Infinity", true, true},
+		{"1e400", "3.3", true, true},
+		{"91", "3.3", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lat+","+tt.lng, func(t *testing.T) {
+			point, err := parseLatLng(tt.lat, tt.lng)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if (point == nil) != tt.wantNil {
+				t.Errorf("point = %+v, want nil %v", point, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestParseFiniteFloat(t *testing.T) {
+	for _, raw := range []string{"NaN", "Inf", "+Inf", "-Inf", "infinity", "1e400", "", "x"} {
+		if value, err := parseFiniteFloat(raw); err == nil {
+			t.Errorf("parseFiniteFloat(%q) = %g, want an error", raw, value)
+		}
+	}
+	if value, err := parseFiniteFloat("2.5"); err != nil || value != 2.5 {
+		t.Errorf("parseFiniteFloat(\"2.5\") = %g, %v", value, err)
+	}
+}
+
+func TestParseProductFilterCurrency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		query        string
+		wantCurrency string
+		wantErr      bool
+	}{
+		{"", "", false},
+		{"currency=USD", "", false},
+		{"currency=USD&min_price=5", "USD", false},
+		{"currency=usd&max_price=5", "USD", false},
+		{"min_price=₦5,000", "NGN", false},
+		{"currency=USD&min_price=₦5,000", "", true},
+		{"price_currency=USD&min_price=5", "", true},
+		{"currency=XYZ", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/products?"+tt.query, nil)
+			filter, err := parseProductFilter(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && filter.Currency != tt.wantCurrency {
+				t.Errorf("currency = %q, want %q", filter.Currency, tt.wantCurrency)
+			}
+		})
+	}
+}
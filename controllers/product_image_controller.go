@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"CROWD_MARKET/services"
+	"errors"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImageOrderRequest struct {
+	Order   []string `json:"order"`
+	Primary string   `json:"primary"`
+}
+
+// imageHeaders collects uploaded files from the repeatable "images" field
+// and the single legacy "image" field
+func imageHeaders(c *gin.Context) []*multipart.FileHeader {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil
+	}
+	var headers []*multipart.FileHeader
+	headers = append(headers, form.File["image"]...)
+	headers = append(headers, form.File["images"]...)
+	return headers
+}
+
+// respondUploadError answers 400 for rejected files and direct uploads and
+// 500 for storage failures
+func respondUploadError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrImageTooLarge) || errors.Is(err, services.ErrUnsupportedImage) ||
+		errors.Is(err, services.ErrUploadNotFound) || errors.Is(err, services.ErrUploadMissing) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload image: " + err.Error()})
+}
+
+func respondImageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrImageTooLarge), errors.Is(err, services.ErrUnsupportedImage),
+		errors.Is(err, services.ErrUploadNotFound), errors.Is(err, services.ErrUploadMissing):
+		respondUploadError(c, err)
+	case errors.Is(err, services.ErrImageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrImagesChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTooManyImages), errors.Is(err, services.ErrLastProductImage), isListingQueryError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondProductWriteError(c, err)
+	}
+}
+
+// 🖼️ Add images to a product (multipart "images" and/or "upload_id" from
+// POST /uploads/sign, both repeatable)
+func AddProductImages(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	if err := services.EnsureCanContribute(userID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	headers := imageHeaders(c)
+	uploadIDs := c.PostFormArray("upload_id")
+	if len(headers)+len(uploadIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one image file or upload_id is required"})
+		return
+	}
+
+	images, err := services.AddProductImages(c.Param("id"), userID.Hex(), headers, uploadIDs)
+	if err != nil {
+		respondImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Images added successfully",
+		"images":  images,
+	})
+}
+
+// 🗑️ Remove one image from a product
+func DeleteProductImage(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	images, err := services.DeleteProductImage(c.Param("id"), userID.Hex(), c.Param("imageId"))
+	if err != nil {
+		respondImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Image deleted successfully",
+		"images":  images,
+	})
+}
+
+// 🔀 Reorder a product's images and optionally choose the primary one
+func ReorderProductImages(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	var request ImageOrderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Order == nil && request.Primary == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order or primary is required"})
+		return
+	}
+
+	images, err := services.ReorderProductImages(c.Param("id"), userID.Hex(), request.Order, request.Primary)
+	if err != nil {
+		respondImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Images reordered successfully",
+		"images":  images,
+	})
+}
+
+// 🧹 Delete stored images no product references (admin). With
+// ?dry_run=true only report what would be deleted.
+func CollectOrphanedImages(c *gin.Context) {
+	dryRun, err := parseOptionalBool(c, "dry_run")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := services.CollectOrphanedImages(dryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	message := "Orphaned images deleted"
+	if dryRun {
+		message = "Dry run, nothing was deleted"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"report":  report,
+	})
+}
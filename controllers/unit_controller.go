@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"CROWD_MARKET/model"
+	"CROWD_MARKET/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UnitRequest struct {
+	Code      string   `json:"code" binding:"required"`
+	Name      string   `json:"name" binding:"required"`
+	Dimension string   `json:"dimension"`
+	Factor    float64  `json:"factor"`
+	Aliases   []string `json:"aliases"`
+}
+
+// 📏 Units accepted on products
+func ListUnits(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Units fetched successfully",
+		"units":   services.ListUnits(),
+	})
+}
+
+// 📏 Register a local measure such as "mudu"
+func CreateUnit(c *gin.Context) {
+	var request UnitRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	unit, err := services.CreateUnit(model.Unit{
+		Code:      request.Code,
+		Name:      request.Name,
+		Dimension: request.Dimension,
+		Factor:    request.Factor,
+		Aliases:   request.Aliases,
+	})
+	if err != nil {
+		respondListingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Unit created successfully",
+		"unit":    unit,
+	})
+}
+
+// 🗑️ Remove a custom measure
+func DeleteUnit(c *gin.Context) {
+	if err := services.DeleteUnit(c.Param("code")); err != nil {
+		if isListingQueryError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unit deleted successfully"})
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ✍️ Sign a direct upload. The client sends the file to the returned target
+// and then passes upload_id to POST /products or POST /products/:id/images.
+// Targets are Cloudinary signed parameters or, for the local store, a signed
+// PUT /uploads/:id URL; S3 presigned URLs are not supported.
+func SignUpload(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	if err := services.EnsureCanContribute(userID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, err := services.SignUpload(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign upload: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Upload signed successfully",
+		"upload":  target,
+	})
+}
+
+// 📥 Receive a signed direct upload for the local image store. The request
+// body is the file; the signature in the URL stands in for a login.
+func ReceiveUpload(c *gin.Context) {
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, config.MaxImageBytes+1)
+	err := services.ReceiveLocalUpload(c.Param("id"), c.Query("expires"), c.Query("signature"), body)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "File uploaded successfully"})
+	case errors.Is(err, services.ErrInvalidUploadSignature):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUploadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrImageTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store upload: " + err.Error()})
+	}
+}
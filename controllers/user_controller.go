@@ -66,3 +66,17 @@ func GetProfile(c *gin.Context) {
 		"email":   email,
 	})
 }
+
+// 👤 Public contributor profile with reputation
+func GetPublicProfile(c *gin.Context) {
+	profile, err := services.GetPublicProfile(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Profile fetched successfully",
+		"profile": profile,
+	})
+}
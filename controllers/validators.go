@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"fmt"
+
+	"CROWD_MARKET/services"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// RegisterValidators adds the app's custom rules to gin's validator so they
+// can be used in `binding` struct tags as well as on single values
+func RegisterValidators() {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	_ = engine.RegisterValidation("category", func(fl validator.FieldLevel) bool {
+		return services.IsKnownCategory(fl.Field().String())
+	})
+}
+
+// validateCategory rejects a product category missing from the taxonomy
+func validateCategory(category string) error {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return nil
+	}
+	if err := engine.Var(category, "category"); err != nil {
+		return fmt.Errorf("%w: %q", services.ErrUnknownCategory, category)
+	}
+	return nil
+}
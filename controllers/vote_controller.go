@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"CROWD_MARKET/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VoteRequest struct {
+	Vote string `json:"vote" binding:"required"`
+}
+
+// 👍 Confirm ("I saw this price too") or dispute a price report
+func CastVote(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var request VoteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := services.CastVote(c.Param("id"), userID.Hex(), request.Vote)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidVote):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrOwnReport), errors.Is(err, services.ErrSuspended):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vote recorded successfully",
+		"product": product,
+	})
+}
+
+// 🗑️ Withdraw a vote
+func RemoveVote(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	product, err := services.RemoveVote(c.Param("id"), userID.Hex())
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vote removed successfully",
+		"product": product,
+	})
+}
@@ -4,10 +4,10 @@ import (
 	"CROWD_MARKET/config"
 	"CROWD_MARKET/model"
 	"context"
+	"errors"
 	"net/http"
 	"os"
 	"path/filepath"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -23,8 +23,11 @@ func AddProduct(c *gin.Context) {
 	description := c.PostForm("description")
 	priceStr := c.PostForm("price")
 
-	// Convert price to float64
-	price, err := strconv.ParseFloat(priceStr, 64)
+	// Parse price into minor units, falling back to the default currency
+	price, err := model.ParseMoney(priceStr, c.PostForm("currency"))
+	if errors.Is(err, model.ErrMissingCurrency) {
+		price, err = model.ParseMoney(priceStr, config.DefaultCurrency)
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price"})
 		return
@@ -53,7 +56,7 @@ func AddProduct(c *gin.Context) {
 		Area:        area,
 		Description: description,
 		ImageURL:    filePath,
-		Price:       price, // assign numeric price
+		Price:       price,
 		CreatedAt:   &now,
 		UpdatedAt:   &now,
 	}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"os"
 	"strings"
@@ -12,61 +13,103 @@ import (
 
 var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
 
+var errMissingAuthHeader = errors.New("Missing Authorization header")
+
 func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing Authorization header"})
+		claims, err := parseBearerToken(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			c.Abort()
 			return
 		}
 
-		// Expected format: "Bearer <token>"
-		// 🧠 Splitting to ensure only 2 parts (Bearer + token)
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Authorization header format"})
-			c.Abort()
-			return
+		setUserContext(c, claims)
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware identifies the caller when a valid token is present
+// but lets everyone else through, so public reads can still personalise. A
+// missing, expired or malformed token is treated as anonymous: a logged-out
+// client holding a stale token must still be able to read.
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, err := parseBearerToken(c); err == nil {
+			setUserContext(c, claims)
 		}
+		c.Next()
+	}
+}
 
-		tokenString := parts[1]
+func parseBearerToken(c *gin.Context) (jwt.MapClaims, error) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return nil, errMissingAuthHeader
+	}
 
-		// ✅ Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return jwtSecret, nil
-		})
+	// Expected format: "Bearer <token>"
+	// 🧠 Splitting to ensure only 2 parts (Bearer + token)
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return nil, errors.New("Invalid Authorization header format")
+	}
 
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token: " + err.Error()})
-			c.Abort()
-			return
+	tokenString := parts[1]
+
+	// ✅ Parse and validate token
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
 		}
+		return jwtSecret, nil
+	})
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
-			return
+	if err != nil {
+		return nil, errors.New("Invalid or expired token: " + err.Error())
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("Invalid token claims")
+	}
+
+	// 🕒 Token expiry check (extra layer)
+	if exp, ok := claims["exp"].(float64); ok {
+		if time.Unix(int64(exp), 0).Before(time.Now()) {
+			return nil, errors.New("Token has expired")
 		}
+	}
 
-		// 🕒 Token expiry check (extra layer)
-		if exp, ok := claims["exp"].(float64); ok {
-			if time.Unix(int64(exp), 0).Before(time.Now()) {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has expired"})
-				c.Abort()
+	return claims, nil
+}
+
+// RequireRole lets a request through only when the token carries one of the
+// given roles. It must run after JWTAuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
 				return
 			}
 		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to perform this action"})
+		c.Abort()
+	}
+}
 
-		// ✅ Save user info in Gin context for later use
-		c.Set("user_id", claims["user_id"])
-		c.Set("email", claims["email"])
-		c.Set("role", claims["role"]) // Optional: useful for admin/vendor checks
-
-		c.Next()
+// ✅ Save user info in Gin context for later use. Only string claims are
+// stored so handlers can rely on c.GetString.
+func setUserContext(c *gin.Context, claims jwt.MapClaims) {
+	if userID, ok := claims["user_id"].(string); ok && userID != "" {
+		c.Set("user_id", userID)
+	}
+	if email, ok := claims["email"].(string); ok {
+		c.Set("email", email)
+	}
+	if role, ok := claims["role"].(string); ok {
+		c.Set("role", role) // Optional: useful for admin/vendor checks
 	}
 }
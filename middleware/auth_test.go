@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func signedToken(t *testing.T, secret []byte, expires time.Time) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "64b7f0c2a1b2c3d4e5f60718",
+		"role":    "user",
+		"exp":     expires.Unix(),
+	})
+	signed, err := token.SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestOptionalAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtSecret = []byte("test-secret")
+
+	tests := []struct {
+		name   string
+		header string
+		userID string
+	}{
+		{"anonymous", "", ""},
+		{"valid token", "Bearer " + signedToken(t, jwtSecret, time.Now().Add(time.Hour)), "64b7f0c2a1b2c3d4e5f60718"},
+		{"expired token", "Bearer " + signedToken(t, jwtSecret, time.Now().Add(-time.Hour)), ""},
+		{"wrong secret", "Bearer " + signedToken(t, []byte("other"), time.Now().Add(time.Hour)), ""},
+		{"malformed token", "Bearer not-a-jwt", ""},
+		{"malformed header", "Token abc", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", OptionalAuthMiddleware(), func(c *gin.Context) {
+				c.String(http.StatusOK, c.GetString("user_id"))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if got := rec.Body.String(); got != tt.userID {
+				t.Errorf("user_id = %q, want %q", got, tt.userID)
+			}
+		})
+	}
+}
+
+func TestJWTAuthMiddlewareRejectsInvalidTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtSecret = []byte("test-secret")
+
+	for name, header := range map[string]string{
+		"anonymous":     "",
+		"expired token": "Bearer " + signedToken(t, jwtSecret, time.Now().Add(-time.Hour)),
+		"wrong secret":  "Bearer " + signedToken(t, []byte("other"), time.Now().Add(time.Hour)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", JWTAuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		role string
+		want int
+	}{
+		{"partner", http.StatusOK},
+		{"admin", http.StatusOK},
+		{"user", http.StatusForbidden},
+		{"moderator", http.StatusForbidden},
+		{"", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/", func(c *gin.Context) {
+				if tt.role != "" {
+					c.Set("role", tt.role)
+				}
+			}, RequireRole("partner", "admin"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Category is a node in the product taxonomy. The slug is the ID stored on
+// products. Ancestors lists parent slugs from the root down so a subtree can
+// be found without walking the tree.
+type Category struct {
+	Slug      string    `bson:"_id" json:"slug"`
+	Parent    string    `bson:"parent,omitempty" json:"parent,omitempty"`
+	Ancestors []string  `bson:"ancestors" json:"ancestors"`
+	Name      string    `bson:"name" json:"name"`
+	Icon      string    `bson:"icon,omitempty" json:"icon,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExchangeRate says one unit of Base buys Rate units of Quote from
+// EffectiveDate (UTC midnight) until the next rate for the same pair.
+type ExchangeRate struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Base          string             `bson:"base" json:"base"`
+	Quote         string             `bson:"quote" json:"quote"`
+	Rate          float64            `bson:"rate" json:"rate"`
+	EffectiveDate time.Time          `bson:"effective_date" json:"effective_date"`
+	Source        string             `bson:"source,omitempty" json:"source,omitempty"`
+	CreatedBy     primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// ConvertedPrice is a price shown in another currency. It is computed per
+// request and never stored.
+type ConvertedPrice struct {
+	Money
+	Rate     float64   `json:"rate"`
+	RateDate time.Time `json:"rate_date"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Item is a canonical catalog entry, e.g. "Rice 50kg", that many price
+// reports point at.
+type Item struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name           string             `bson:"name" json:"name"`
+	NormalizedName string             `bson:"normalized_name" json:"-"`
+	Category       string             `bson:"category" json:"category"`
+	Unit           string             `bson:"unit" json:"unit"`
+	Aliases        []string           `bson:"aliases" json:"aliases"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Price report review states. Reports without a status predate scoring and
+// count as published. Removed marks the report of a hidden or deleted
+// listing; it is kept for history but left out of aggregates.
+const (
+	ReportStatusPublished = "published"
+	ReportStatusSuspect   = "suspect"
+	ReportStatusPending   = "pending"
+	ReportStatusRemoved   = "removed"
+)
+
+// PriceReport is one observation of an item's price in an area.
+// OutlierScore is the robust z-score against recent reports for the same
+// item and area; it is nil when there were too few reports to compare.
+// Weight is the reporter's reputation at submission and weights aggregates.
+type PriceReport struct {
+	ID           primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
+	ItemID       primitive.ObjectID   `bson:"item_id" json:"item_id"`
+	ProductID    primitive.ObjectID   `bson:"product_id,omitempty" json:"product_id"`
+	ReporterID   primitive.ObjectID   `bson:"reporter_id" json:"reporter_id"`
+	Area         string               `bson:"area" json:"area"`
+	LocationID   *primitive.ObjectID  `bson:"location_id,omitempty" json:"location_id,omitempty"`
+	LocationPath []primitive.ObjectID `bson:"location_path,omitempty" json:"-"`
+	MarketID     *primitive.ObjectID  `bson:"market_id,omitempty" json:"market_id,omitempty"`
+	Price        Money                `bson:"price" json:"price"`
+	Location     *GeoPoint            `bson:"location,omitempty" json:"location,omitempty"`
+	ObservedAt   time.Time            `bson:"observed_at" json:"observed_at"`
+	Status       string               `bson:"status" json:"status"`
+	OutlierScore *float64             `bson:"outlier_score,omitempty" json:"outlier_score,omitempty"`
+	Weight       float64              `bson:"weight" json:"weight"`
+	CreatedAt    time.Time            `bson:"created_at" json:"created_at"`
+}
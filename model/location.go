@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Location levels, from widest to narrowest
+const (
+	LocationCountry = "country"
+	LocationState   = "state"
+	LocationCity    = "city"
+	LocationArea    = "area"
+	LocationMarket  = "market"
+)
+
+// LocationLevels maps each level to its depth in the hierarchy
+var LocationLevels = map[string]int{
+	LocationCountry: 0,
+	LocationState:   1,
+	LocationCity:    2,
+	LocationArea:    3,
+	LocationMarket:  3,
+}
+
+// Location is a node in the country → state → city → area/market hierarchy.
+// Ancestors holds parent IDs from the country down; Path is the display
+// form, e.g. "Yaba, Lagos, Lagos State, Nigeria". SearchTerms are the
+// normalized name and aliases used by autocomplete.
+type Location struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name        string               `bson:"name" json:"name"`
+	Type        string               `bson:"type" json:"type"`
+	ParentID    *primitive.ObjectID  `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Ancestors   []primitive.ObjectID `bson:"ancestors" json:"ancestors"`
+	Path        string               `bson:"path" json:"path"`
+	Aliases     []string             `bson:"aliases,omitempty" json:"aliases,omitempty"`
+	SearchTerms []string             `bson:"search_terms" json:"-"`
+	CreatedAt   time.Time            `bson:"created_at" json:"created_at"`
+}
+
+// Lineage is the location followed by its ancestors, nearest first, as stored
+// on products and price reports for "within this location" filters
+func (l Location) Lineage() []primitive.ObjectID {
+	lineage := []primitive.ObjectID{l.ID}
+	for i := len(l.Ancestors) - 1; i >= 0; i-- {
+		lineage = append(lineage, l.Ancestors[i])
+	}
+	return lineage
+}
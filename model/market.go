@@ -0,0 +1,91 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Market types
+const (
+	MarketOpen        = "open_market"
+	MarketSupermarket = "supermarket"
+	MarketRoadside    = "roadside"
+)
+
+var MarketTypes = map[string]bool{
+	MarketOpen:        true,
+	MarketSupermarket: true,
+	MarketRoadside:    true,
+}
+
+// Weekdays in the short form used by opening hours
+var Weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// OpeningHours is one opening window on the listed days. Times are "HH:MM"
+// in the market's time zone; a close before the open runs past midnight.
+type OpeningHours struct {
+	Days  []string `bson:"days" json:"days"`
+	Open  string   `bson:"open" json:"open"`
+	Close string   `bson:"close" json:"close"`
+}
+
+// Market is a place prices are observed at, such as Mile 12 Market or a
+// particular supermarket
+type Market struct {
+	ID           primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name         string               `bson:"name" json:"name"`
+	Type         string               `bson:"type" json:"type"`
+	Address      string               `bson:"address,omitempty" json:"address,omitempty"`
+	LocationID   *primitive.ObjectID  `bson:"location_id,omitempty" json:"location_id,omitempty"`
+	LocationPath []primitive.ObjectID `bson:"location_path,omitempty" json:"-"`
+	Geo          *GeoPoint            `bson:"geo,omitempty" json:"geo,omitempty"`
+	Hours        []OpeningHours       `bson:"hours" json:"hours"`
+	Timezone     string               `bson:"timezone,omitempty" json:"timezone,omitempty"`
+	CreatedBy    primitive.ObjectID   `bson:"created_by" json:"created_by"`
+	CreatedAt    time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// ParseClock reads an "HH:MM" time as minutes after midnight
+func ParseClock(raw string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(raw, "%d:%d", &hour, &minute); err != nil || hour < 0 || hour > 24 || minute < 0 || minute > 59 || (hour == 24 && minute > 0) {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", raw)
+	}
+	return hour*60 + minute, nil
+}
+
+// IsOpenAt reports whether the market is open at t, read in zone. Windows
+// that run past midnight count toward the day they open on.
+func (m Market) IsOpenAt(t time.Time, zone *time.Location) bool {
+	local := t.In(zone)
+	now := local.Hour()*60 + local.Minute()
+	today := Weekdays[local.Weekday()]
+	yesterday := Weekdays[(local.Weekday()+6)%7]
+
+	for _, window := range m.Hours {
+		open, err := ParseClock(window.Open)
+		if err != nil {
+			continue
+		}
+		close, err := ParseClock(window.Close)
+		if err != nil {
+			continue
+		}
+		for _, day := range window.Days {
+			if close > open {
+				if day == today && now >= open && now < close {
+					return true
+				}
+				continue
+			}
+			// Overnight: open late today, or still open from yesterday
+			if (day == today && now >= open) || (day == yesterday && now < close) {
+				return true
+			}
+		}
+	}
+	return false
+}
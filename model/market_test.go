@@ -0,0 +1,87 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    int
+		wantErr bool
+	}{
+		{"08:00", 480, false},
+		{"7:05", 425, false},
+		{"00:00", 0, false},
+		{"24:00", 1440, false},
+		{"24:01", 0, true},
+		{"25:00", 0, true},
+		{"12:60", 0, true},
+		{"-1:00", 0, true},
+		{"noon", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseClock(tt.raw)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseClock(%q) = %d, %v, want %d (error %v)", tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestMarketIsOpenAt(t *testing.T) {
+	wat := time.FixedZone("WAT", 60*60)
+	// 2024-01-01 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, 0, 0, wat)
+	}
+	market := Market{Hours: []OpeningHours{
+		{Days: []string{"mon", "tue"}, Open: "08:00", Close: "18:00"},
+		{Days: []string{"fri"}, Open: "22:00", Close: "02:00"},
+		{Days: []string{"sat"}, Open: "23:00", Close: "03:00"},
+		{Days: []string{"wed"}, Open: "late", Close: "18:00"},
+	}}
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"before opening", at(1, 7, 59), false},
+		{"at opening", at(1, 8, 0), true},
+		{"before closing", at(1, 17, 59), true},
+		{"at closing", at(1, 18, 0), false},
+		{"second listed day", at(2, 12, 0), true},
+		{"unlisted day", at(4, 12, 0), false},
+		{"invalid window is skipped", at(3, 12, 0), false},
+		{"overnight before opening", at(5, 21, 59), false},
+		{"overnight at opening", at(5, 22, 0), true},
+		{"overnight before midnight", at(5, 23, 59), true},
+		{"overnight after midnight", at(6, 1, 59), true},
+		{"overnight at closing", at(6, 2, 0), false},
+		{"overnight hours do not open the day before", at(4, 23, 0), false},
+		{"overnight from saturday into sunday", at(7, 2, 30), true},
+		{"overnight from saturday closed on sunday", at(7, 3, 0), false},
+		{"sunday night is not saturday", at(7, 23, 30), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := market.IsOpenAt(tt.t, wat); got != tt.want {
+				t.Errorf("IsOpenAt(%s) = %v, want %v", tt.t.Format("Mon 15:04"), got, tt.want)
+			}
+		})
+	}
+
+	// The same instant reads differently in another zone: 07:30 UTC is
+	// 08:30 in Lagos
+	instant := time.Date(2024, 1, 1, 7, 30, 0, 0, time.UTC)
+	if !market.IsOpenAt(instant, wat) {
+		t.Error("closed at 08:30 WAT")
+	}
+	if market.IsOpenAt(instant, time.UTC) {
+		t.Error("open at 07:30 UTC")
+	}
+}
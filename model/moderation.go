@@ -0,0 +1,83 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// User roles. Partners are data providers allowed to bulk import reports.
+const (
+	RoleUser      = "user"
+	RolePartner   = "partner"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Account states
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+)
+
+// Reason codes accepted on POST /products/:id/report
+const (
+	FlagReasonSpam          = "spam"
+	FlagReasonFakePrice     = "fake_price"
+	FlagReasonOffensive     = "offensive"
+	FlagReasonWrongCategory = "wrong_category"
+	FlagReasonDuplicate     = "duplicate"
+	FlagReasonOther         = "other"
+)
+
+var FlagReasons = map[string]bool{
+	FlagReasonSpam:          true,
+	FlagReasonFakePrice:     true,
+	FlagReasonOffensive:     true,
+	FlagReasonWrongCategory: true,
+	FlagReasonDuplicate:     true,
+	FlagReasonOther:         true,
+}
+
+// Flag states
+const (
+	FlagStatusOpen      = "open"
+	FlagStatusResolved  = "resolved"
+	FlagStatusDismissed = "dismissed"
+)
+
+// Moderator actions on products and users
+const (
+	ModActionHide      = "hide"
+	ModActionRestore   = "restore"
+	ModActionApprove   = "approve"
+	ModActionDelete    = "delete"
+	ModActionDismiss   = "dismiss"
+	ModActionWarn      = "warn"
+	ModActionSuspend   = "suspend"
+	ModActionUnsuspend = "unsuspend"
+)
+
+// ModerationFlag is a user's report that a listing is fake or offensive.
+type ModerationFlag struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	ProductID  primitive.ObjectID  `bson:"product_id" json:"product_id"`
+	ReporterID primitive.ObjectID  `bson:"reporter_id" json:"reporter_id"`
+	Reason     string              `bson:"reason" json:"reason"`
+	Note       string              `bson:"note,omitempty" json:"note,omitempty"`
+	Status     string              `bson:"status" json:"status"`
+	ResolvedBy *primitive.ObjectID `bson:"resolved_by,omitempty" json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time          `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// ModerationAction is the audit log entry for every moderator decision.
+type ModerationAction struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	ModeratorID  primitive.ObjectID  `bson:"moderator_id" json:"moderator_id"`
+	Action       string              `bson:"action" json:"action"`
+	ProductID    *primitive.ObjectID `bson:"product_id,omitempty" json:"product_id,omitempty"`
+	TargetUserID *primitive.ObjectID `bson:"target_user_id,omitempty" json:"target_user_id,omitempty"`
+	Note         string              `bson:"note" json:"note"`
+	CreatedAt    time.Time           `bson:"created_at" json:"created_at"`
+}
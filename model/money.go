@@ -0,0 +1,338 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"CROWD_MARKET/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Money is an amount in the currency's minor units (kobo, pesewas, cents)
+// with its ISO 4217 code. Amounts are never stored as floating point.
+type Money struct {
+	Amount   int64  `bson:"amount" json:"amount"`
+	Currency string `bson:"currency" json:"currency"`
+}
+
+// Minor-unit exponents of the currencies we accept
+var currencyExponents = map[string]int{
+	"NGN": 2,
+	"GHS": 2,
+	"XOF": 0,
+	"XAF": 0,
+	"KES": 2,
+	"ZAR": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+}
+
+// Symbols recognised in price input. Longer symbols are matched first.
+var currencySymbols = []struct {
+	symbol   string
+	currency string
+}{
+	{"GH₵", "GHS"},
+	{"GH¢", "GHS"},
+	{"FCFA", "XOF"},
+	{"CFA", "XOF"},
+	{"KSh", "KES"},
+	{"₦", "NGN"},
+	{"₵", "GHS"},
+	{"$", "USD"},
+	{"€", "EUR"},
+	{"£", "GBP"},
+}
+
+var (
+	ErrInvalidAmount   = errors.New("invalid price amount")
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrMissingCurrency = errors.New("price currency is required")
+)
+
+// CurrencyExponent reports how many decimal places a currency has
+func CurrencyExponent(currency string) (int, bool) {
+	exp, ok := currencyExponents[currency]
+	return exp, ok
+}
+
+// NormalizeCurrency upper-cases a code and checks it is supported
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := currencyExponents[code]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return code, nil
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+// Major is the amount in major units. Use it for display and statistics
+// only, never to store or compare prices.
+func (m Money) Major() float64 {
+	exp, _ := CurrencyExponent(m.Currency)
+	return float64(m.Amount) / float64(pow10(exp))
+}
+
+// Decimal renders the amount as a plain decimal string, e.g. "1500.50"
+func (m Money) Decimal() string {
+	exp, _ := CurrencyExponent(m.Currency)
+	if exp == 0 {
+		return fmt.Sprintf("%d", m.Amount)
+	}
+	sign, amount := "", m.Amount
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	unit := pow10(exp)
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/unit, exp, amount%unit)
+}
+
+func (m Money) String() string {
+	return m.Currency + " " + m.Decimal()
+}
+
+// MoneyFromMajor converts a legacy floating-point price, rounding to the
+// nearest minor unit.
+func MoneyFromMajor(value float64, currency string) (Money, error) {
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) || value < 0 {
+		return Money{}, ErrInvalidAmount
+	}
+	exp, _ := CurrencyExponent(currency)
+	scaled := math.Round(value * float64(pow10(exp)))
+	if scaled > math.MaxInt64/2 {
+		return Money{}, ErrInvalidAmount
+	}
+	return Money{Amount: int64(scaled), Currency: currency}, nil
+}
+
+// LegacyMoney converts a price stored as a bare BSON number, from before
+// prices carried a currency, reading it as major units of currency. Decimals
+// are parsed from their string form so no precision is lost.
+func LegacyMoney(value bson.RawValue, currency string) (Money, error) {
+	switch value.Type {
+	case bson.TypeDouble:
+		return MoneyFromMajor(value.Double(), currency)
+	case bson.TypeInt32:
+		return MoneyFromMajor(float64(value.Int32()), currency)
+	case bson.TypeInt64:
+		return MoneyFromMajor(float64(value.Int64()), currency)
+	case bson.TypeDecimal128:
+		return moneyFromDecimal128(value.Decimal128(), currency)
+	}
+	return Money{}, fmt.Errorf("%w: BSON %s", ErrInvalidAmount, value.Type)
+}
+
+// moneyFromDecimal128 converts exactly; digits beyond the currency's minor
+// unit are an error rather than being rounded away
+func moneyFromDecimal128(d primitive.Decimal128, currency string) (Money, error) {
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	coefficient, exp, err := d.BigInt()
+	if err != nil || coefficient.Sign() < 0 {
+		return Money{}, ErrInvalidAmount
+	}
+	minorExp, _ := CurrencyExponent(currency)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp+minorExp))), nil)
+	if exp+minorExp >= 0 {
+		coefficient.Mul(coefficient, scale)
+	} else if _, rem := coefficient.QuoRem(coefficient, scale, new(big.Int)); rem.Sign() != 0 {
+		return Money{}, fmt.Errorf("%s prices have at most %d decimal places", currency, minorExp)
+	}
+	if !coefficient.IsInt64() {
+		return Money{}, ErrInvalidAmount
+	}
+	return Money{Amount: coefficient.Int64(), Currency: currency}, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// UnmarshalBSONValue reads Money documents and, until the money migration
+// has run, legacy numeric prices in DEFAULT_CURRENCY
+func (m *Money) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bson.TypeEmbeddedDocument:
+		type plainMoney Money
+		var plain plainMoney
+		if err := value.Unmarshal(&plain); err != nil {
+			return err
+		}
+		*m = Money(plain)
+		return nil
+	case bson.TypeNull, bson.TypeUndefined:
+		*m = Money{}
+		return nil
+	}
+	legacy, err := LegacyMoney(value, config.DefaultCurrency)
+	if err != nil {
+		return fmt.Errorf("legacy price: %w", err)
+	}
+	*m = legacy
+	return nil
+}
+
+// ParseMoney reads a price as people type it: "₦1,500.50", "1 500 FCFA",
+// "GHS 20" or plain "1500.5". A currency symbol or code in the input wins
+// over currency, which is used when the input has neither; the two must
+// agree when both are present. The amount is parsed digit by digit so no
+// precision is lost, and more decimals than the currency allows is an error.
+func ParseMoney(input, currency string) (Money, error) {
+	rest, found := splitCurrency(strings.TrimSpace(input))
+	if currency != "" {
+		normalized, err := NormalizeCurrency(currency)
+		if err != nil {
+			return Money{}, err
+		}
+		if found != "" && found != normalized {
+			return Money{}, fmt.Errorf("price is in %s but currency is %s", found, normalized)
+		}
+		currency = normalized
+	}
+	if found != "" {
+		currency = found
+	}
+	if currency == "" {
+		return Money{}, ErrMissingCurrency
+	}
+
+	exp, _ := CurrencyExponent(currency)
+	whole, fraction, err := splitAmount(rest)
+	if err != nil {
+		return Money{}, err
+	}
+	if len(fraction) > exp {
+		return Money{}, fmt.Errorf("%s prices have at most %d decimal places", currency, exp)
+	}
+	fraction += strings.Repeat("0", exp-len(fraction))
+
+	var amount int64
+	for _, r := range whole + fraction {
+		digit := int64(r - '0')
+		if amount > (math.MaxInt64-digit)/10 {
+			return Money{}, ErrInvalidAmount
+		}
+		amount = amount*10 + digit
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// splitCurrency strips a leading or trailing currency symbol or ISO code
+func splitCurrency(input string) (string, string) {
+	for _, s := range currencySymbols {
+		if strings.HasPrefix(input, s.symbol) {
+			return strings.TrimSpace(strings.TrimPrefix(input, s.symbol)), s.currency
+		}
+		if strings.HasSuffix(input, s.symbol) {
+			return strings.TrimSpace(strings.TrimSuffix(input, s.symbol)), s.currency
+		}
+	}
+	if len(input) > 3 {
+		if code, err := NormalizeCurrency(input[:3]); err == nil && !unicode.IsLetter(rune(input[3])) {
+			return strings.TrimSpace(input[3:]), code
+		}
+		if code, err := NormalizeCurrency(input[len(input)-3:]); err == nil && !unicode.IsLetter(rune(input[len(input)-4])) {
+			return strings.TrimSpace(input[:len(input)-3]), code
+		}
+	}
+	return input, ""
+}
+
+// splitAmount separates the whole and fractional digits of a localized
+// number. When both "," and "." appear the last one is the decimal mark. A
+// lone separator followed by exactly three digits groups thousands;
+// otherwise it is the decimal mark. Spaces group thousands.
+func splitAmount(input string) (string, string, error) {
+	s := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\u00a0' || r == '\u202f' || r == '\'' {
+			return -1
+		}
+		return r
+	}, input)
+	if s == "" {
+		return "", "", ErrInvalidAmount
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && r != ',' && r != '.' {
+			return "", "", ErrInvalidAmount
+		}
+	}
+
+	decimal := ""
+	lastComma, lastDot := strings.LastIndex(s, ","), strings.LastIndex(s, ".")
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		decimal = ","
+		if lastDot > lastComma {
+			decimal = "."
+		}
+	case lastComma >= 0 || lastDot >= 0:
+		sep := ","
+		if lastDot >= 0 {
+			sep = "."
+		}
+		if strings.Count(s, sep) == 1 && len(s)-strings.Index(s, sep)-1 != 3 {
+			decimal = sep
+		}
+	}
+
+	whole, fraction := s, ""
+	if decimal != "" {
+		i := strings.LastIndex(s, decimal)
+		whole, fraction = s[:i], s[i+1:]
+		if strings.ContainsAny(fraction, ",.") {
+			return "", "", ErrInvalidAmount
+		}
+	}
+
+	// Remaining separators must group the whole part in threes
+	if strings.Contains(whole, ",") && strings.Contains(whole, ".") {
+		return "", "", ErrInvalidAmount
+	}
+	groups := strings.FieldsFunc(whole, func(r rune) bool { return r == ',' || r == '.' })
+	if len(groups) > 1 || strings.ContainsAny(whole, ",.") {
+		if strings.HasPrefix(whole, ",") || strings.HasPrefix(whole, ".") ||
+			strings.HasSuffix(whole, ",") || strings.HasSuffix(whole, ".") ||
+			strings.Contains(whole, ",.") || strings.Contains(whole, ".,") {
+			return "", "", ErrInvalidAmount
+		}
+		if len(groups[0]) > 3 {
+			return "", "", ErrInvalidAmount
+		}
+		for _, g := range groups[1:] {
+			if len(g) != 3 {
+				return "", "", ErrInvalidAmount
+			}
+		}
+	}
+	whole = strings.Join(groups, "")
+	if whole == "" && fraction == "" {
+		return "", "", ErrInvalidAmount
+	}
+	return whole, fraction, nil
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"testing"
+
+	"CROWD_MARKET/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMoneyUnmarshalBSONValue(t *testing.T) {
+	config.DefaultCurrency = "NGN"
+	decimal, _ := primitive.ParseDecimal128("1500.25")
+	precise, _ := primitive.ParseDecimal128("0.125")
+	exponent, _ := primitive.ParseDecimal128("1.5E+3")
+	trailing, _ := primitive.ParseDecimal128("12.500")
+
+	tests := []struct {
+		name    string
+		price   interface{}
+		want    Money
+		wantErr bool
+	}{
+		{"money document", Money{Amount: 150050, Currency: "GHS"}, Money{Amount: 150050, Currency: "GHS"}, false},
+		{"legacy double", 1500.5, Money{Amount: 150050, Currency: "NGN"}, false},
+		{"legacy int32", int32(1500), Money{Amount: 150000, Currency: "NGN"}, false},
+		{"legacy int64", int64(1500), Money{Amount: 150000, Currency: "NGN"}, false},
+		{"legacy decimal", decimal, Money{Amount: 150025, Currency: "NGN"}, false},
+		{"legacy decimal exponent", exponent, Money{Amount: 150000, Currency: "NGN"}, false},
+		{"legacy decimal trailing zeros", trailing, Money{Amount: 1250, Currency: "NGN"}, false},
+		{"null", nil, Money{}, false},
+		{"decimal beyond minor units", precise, Money{}, true},
+		{"negative", -5.0, Money{}, true},
+		{"string", "1500", Money{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := bson.Marshal(bson.M{"price": tt.price})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc struct {
+				Price Money `bson:"price"`
+			}
+			err = bson.Unmarshal(data, &doc)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("price = %v, want an error", doc.Price)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc.Price != tt.want {
+				t.Errorf("price = %v, want %v", doc.Price, tt.want)
+			}
+		})
+	}
+}
@@ -7,14 +7,54 @@ import (
 )
 
 type Product struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	UserID      primitive.ObjectID `bson:"user_id,omitempty" json:"user_id"`
-	Name        string             `bson:"name" json:"name" binding:"required"`
-	Price       float64            `bson:"price" json:"price" binding:"required"`
-	Area        string             `bson:"area" json:"area" binding:"required"`
-	Description string             `bson:"description" json:"description" binding:"required"`
-	ImageURL    string             `bson:"image_url" json:"image_url"`
-	Category    string             `bson:"category" json:"category" binding:"category"`
-	CreatedAt   *time.Time         `bson:"created_at" json:"created_at"`
-	UpdatedAt   *time.Time         `bson:"updated_at" json:"updated_at"`
+	ID            primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID        primitive.ObjectID   `bson:"user_id,omitempty" json:"user_id"`
+	ItemID        primitive.ObjectID   `bson:"item_id,omitempty" json:"item_id"`
+	Name          string               `bson:"name" json:"name" binding:"required"`
+	Price         Money                `bson:"price" json:"price" binding:"required"`
+	Converted     *ConvertedPrice      `bson:"-" json:"converted_price,omitempty"`
+	Quantity      float64              `bson:"quantity,omitempty" json:"quantity,omitempty"`
+	Unit          string               `bson:"unit,omitempty" json:"unit,omitempty"`
+	UnitPrice     *UnitPrice           `bson:"unit_price,omitempty" json:"unit_price,omitempty"`
+	Area          string               `bson:"area" json:"area" binding:"required"`
+	LocationID    *primitive.ObjectID  `bson:"location_id,omitempty" json:"location_id,omitempty"`
+	LocationPath  []primitive.ObjectID `bson:"location_path,omitempty" json:"-"`
+	MarketID      *primitive.ObjectID  `bson:"market_id,omitempty" json:"market_id,omitempty"`
+	Description   string               `bson:"description" json:"description" binding:"required"`
+	ImageURL      string               `bson:"image_url" json:"image_url"`
+	Images        []ProductImage       `bson:"images,omitempty" json:"images,omitempty"`
+	Category      string               `bson:"category" json:"category" binding:"category"`
+	Location      *GeoPoint            `bson:"location,omitempty" json:"location,omitempty"`
+	Status        string               `bson:"status,omitempty" json:"status,omitempty"`
+	Hidden        bool                 `bson:"hidden,omitempty" json:"hidden,omitempty"`
+	DeletedAt     *time.Time           `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy     *primitive.ObjectID  `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	Confirmations int                  `bson:"confirmations" json:"confirmations"`
+	Disputes      int                  `bson:"disputes" json:"disputes"`
+	Confidence    float64              `bson:"confidence" json:"confidence"`
+	CreatedAt     *time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt     *time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// ProductImage is one photo of a product. Images are shown in Position
+// order; ImageURL on the product mirrors the primary image for clients that
+// only show one.
+type ProductImage struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	URL       string             `bson:"url" json:"url"`
+	Thumbnail string             `bson:"thumbnail_url,omitempty" json:"thumbnail_url,omitempty"`
+	Medium    string             `bson:"medium_url,omitempty" json:"medium_url,omitempty"`
+	Position  int                `bson:"position" json:"position"`
+	Primary   bool               `bson:"primary" json:"primary"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// GeoPoint is a GeoJSON point. Coordinates are [longitude, latitude].
+type GeoPoint struct {
+	Type        string    `bson:"type" json:"type"`
+	Coordinates []float64 `bson:"coordinates" json:"coordinates"`
+}
+
+func NewGeoPoint(lat, lng float64) *GeoPoint {
+	return &GeoPoint{Type: "Point", Coordinates: []float64{lng, lat}}
 }
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kinds of change recorded in a product's history
+const (
+	RevisionCreate   = "create"
+	RevisionUpdate   = "update"
+	RevisionDelete   = "delete"
+	RevisionRestore  = "restore"
+	RevisionModerate = "moderate"
+)
+
+// FieldChange is one field's value before and after a revision. Old is nil
+// for fields that were unset.
+type FieldChange struct {
+	Field string      `bson:"field" json:"field"`
+	Old   interface{} `bson:"old" json:"old"`
+	New   interface{} `bson:"new" json:"new"`
+}
+
+// ProductRevision is an append-only record of one change to a product.
+// ActorID is left out of the public history when someone other than the
+// owner made the change.
+type ProductRevision struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	ProductID primitive.ObjectID  `bson:"product_id" json:"product_id"`
+	ActorID   *primitive.ObjectID `bson:"actor_id" json:"actor_id,omitempty"`
+	Action    string              `bson:"action" json:"action"`
+	Changes   []FieldChange       `bson:"changes,omitempty" json:"changes,omitempty"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Unit dimensions. Prices of units in the same dimension are compared per
+// base unit: kg for mass, litre for volume and piece for counts. Custom
+// measures (a "paint bucket" of garri) have no fixed conversion and are only
+// compared with the same measure.
+const (
+	DimensionMass   = "mass"
+	DimensionVolume = "volume"
+	DimensionCount  = "count"
+	DimensionCustom = "custom"
+)
+
+// BaseUnits maps each fixed dimension to the unit prices are normalized to
+var BaseUnits = map[string]string{
+	DimensionMass:   "kg",
+	DimensionVolume: "l",
+	DimensionCount:  "piece",
+}
+
+// Unit is an entry in the unit registry. Factor is how many base units one
+// of this unit holds (g = 0.001 kg); it is 1 for custom measures.
+type Unit struct {
+	Code      string    `bson:"_id" json:"code"`
+	Name      string    `bson:"name" json:"name"`
+	Dimension string    `bson:"dimension" json:"dimension"`
+	Factor    float64   `bson:"factor" json:"factor"`
+	Aliases   []string  `bson:"aliases,omitempty" json:"aliases,omitempty"`
+	Builtin   bool      `bson:"-" json:"builtin"`
+	CreatedAt time.Time `bson:"created_at,omitempty" json:"created_at,omitempty"`
+}
+
+// UnitPrice is a price normalized to one base unit (or one custom measure)
+type UnitPrice struct {
+	Money `bson:",inline"`
+	Per   string `bson:"per" json:"per"`
+}
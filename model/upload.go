@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Upload states
+const (
+	UploadPending = "pending"
+	UploadClaimed = "claimed"
+)
+
+// Upload is a signed slot a client uploads an image to directly, bypassing
+// the API. It is claimed once a product references it.
+type Upload struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Key       string             `bson:"key" json:"-"`
+	Status    string             `bson:"status" json:"status"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	ClaimedAt *time.Time         `bson:"claimed_at,omitempty" json:"claimed_at,omitempty"`
+}
@@ -12,8 +12,26 @@ type User struct {
 	Email            string             `bson:"email" json:"email"`
 	Password         string             `bson:"password,omitempty" json:"password,omitempty"`
 	Provider         string             `bson:"provider" json:"provider"`
+	Role             string             `bson:"role,omitempty" json:"role,omitempty"`
+	Status           string             `bson:"status,omitempty" json:"status,omitempty"`
+	Warnings         int                `bson:"warnings" json:"warnings"`
+	SuspendedUntil   *time.Time         `bson:"suspendedUntil,omitempty" json:"suspendedUntil,omitempty"`
 	IsVerified       bool               `bson:"isVerified" json:"isVerified"`
 	VerificationCode string             `bson:"verificationCode,omitempty" json:"verificationCode,omitempty"`
+	Reputation       Reputation         `bson:"reputation" json:"reputation"`
 	CreatedAt        time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt        time.Time          `bson:"updatedAt" json:"updatedAt"`
 }
+
+// Reputation tracks how reliable a contributor's reports have been. The
+// counters are updated incrementally as events arrive and Score is derived
+// from them; a zero UpdatedAt means no events have been recorded yet.
+type Reputation struct {
+	Score              float64   `bson:"score" json:"score"`
+	Confirmations      int       `bson:"confirmations" json:"confirmations"`
+	Disputes           int       `bson:"disputes" json:"disputes"`
+	ModeratorPenalties int       `bson:"moderatorPenalties" json:"moderatorPenalties"`
+	ConsensusReports   int       `bson:"consensusReports" json:"consensusReports"`
+	ConsensusErrorSum  float64   `bson:"consensusErrorSum" json:"-"`
+	UpdatedAt          time.Time `bson:"updatedAt" json:"updatedAt"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Vote kinds on a price report
+const (
+	VoteConfirm = "confirm"
+	VoteDispute = "dispute"
+)
+
+// ReportVote is one user's confirmation or dispute of a price report.
+// There is at most one vote per user per report.
+type ReportVote struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	VoterID   primitive.ObjectID `bson:"voter_id" json:"voter_id"`
+	Vote      string             `bson:"vote" json:"vote"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
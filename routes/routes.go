@@ -3,6 +3,7 @@ package routes
 import (
 	"CROWD_MARKET/controllers"
 	"CROWD_MARKET/middleware"
+	"CROWD_MARKET/model"
 	"CROWD_MARKET/services"
 	"net/http"
 
@@ -10,6 +11,7 @@ import (
 )
 
 func RegisterRoutes(router *gin.Engine) {
+	controllers.RegisterValidators()
 
 	// --- Auth routes ---
 	router.POST("/register", controllers.RegisterUser)
@@ -37,20 +39,101 @@ func RegisterRoutes(router *gin.Engine) {
 		c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully!"})
 	})
 
-	// --- Product routes ---
+	// --- Public product routes (token optional) ---
 	productRoutes := router.Group("/products")
+	productRoutes.Use(middleware.OptionalAuthMiddleware())
 	{
-		productRoutes.POST("/", controllers.AddProduct)
-		productRoutes.GET("/", controllers.GetAllProducts)
+		productRoutes.GET("/", controllers.ListProducts)
+		productRoutes.GET("/search", controllers.SearchProducts)
+		productRoutes.GET("/nearby", controllers.GetNearbyProducts)
 		productRoutes.GET("/:id", controllers.GetProductByID)
-		productRoutes.PUT("/:id", controllers.UpdateProduct)
-		productRoutes.DELETE("/:id", controllers.DeleteProduct)
+		productRoutes.GET("/:id/history", controllers.GetProductHistory)
 	}
 
+	// --- Product write routes (JWT required) ---
+	productWrites := router.Group("/products")
+	productWrites.Use(middleware.JWTAuthMiddleware())
+	{
+		productWrites.POST("/", controllers.AddProduct)
+		productWrites.PUT("/:id", controllers.UpdateProduct)
+		productWrites.DELETE("/:id", controllers.DeleteProduct)
+		productWrites.POST("/:id/restore", controllers.RestoreProduct)
+		productWrites.POST("/:id/votes", controllers.CastVote)
+		productWrites.DELETE("/:id/votes", controllers.RemoveVote)
+		productWrites.POST("/:id/report", controllers.FlagProduct)
+		productWrites.POST("/:id/images", controllers.AddProductImages)
+		productWrites.PUT("/:id/images/order", controllers.ReorderProductImages)
+		productWrites.DELETE("/:id/images/:imageId", controllers.DeleteProductImage)
+	}
+
+	// --- Direct uploads: signing needs a login, the upload itself is
+	// authorised by the signature ---
+	router.POST("/uploads/sign", middleware.JWTAuthMiddleware(), controllers.SignUpload)
+	router.PUT("/uploads/:id", controllers.ReceiveUpload)
+
+	// --- Bulk price report imports (partner or admin role required) ---
+	router.POST("/imports", middleware.JWTAuthMiddleware(), middleware.RequireRole(model.RolePartner, model.RoleAdmin), controllers.ImportProducts)
+
+	// --- Item catalog routes ---
+	itemRoutes := router.Group("/items")
+	{
+		itemRoutes.GET("/", controllers.ListItems)
+		itemRoutes.GET("/:id", controllers.GetItemByID)
+		itemRoutes.GET("/:id/reports", controllers.GetItemReports)
+		itemRoutes.GET("/:id/stats", controllers.GetItemStats)
+		itemRoutes.GET("/:id/history", controllers.GetItemHistory)
+	}
+
+	// --- Moderation routes (moderator or admin role required) ---
+	moderation := router.Group("/moderation")
+	moderation.Use(middleware.JWTAuthMiddleware(), middleware.RequireRole(model.RoleModerator, model.RoleAdmin))
+	{
+		moderation.GET("/queue", controllers.GetModerationQueue)
+		moderation.GET("/reviews", controllers.GetReviewQueue)
+		moderation.GET("/actions", controllers.ListModerationActions)
+		moderation.POST("/products/:id/actions", controllers.ModerateProduct)
+		moderation.POST("/users/:id/actions", controllers.ModerateUser)
+	}
+
+	// --- Admin routes (admin role required) ---
+	admin := router.Group("/admin")
+	admin.Use(middleware.JWTAuthMiddleware(), middleware.RequireRole(model.RoleAdmin))
+	{
+		admin.GET("/exchange-rates", controllers.ListExchangeRates)
+		admin.POST("/exchange-rates", controllers.CreateExchangeRate)
+		admin.POST("/exchange-rates/import", controllers.ImportExchangeRates)
+		admin.PUT("/exchange-rates/:id", controllers.UpdateExchangeRate)
+		admin.DELETE("/exchange-rates/:id", controllers.DeleteExchangeRate)
+		admin.POST("/units", controllers.CreateUnit)
+		admin.DELETE("/units/:code", controllers.DeleteUnit)
+		admin.POST("/categories", controllers.CreateCategory)
+		admin.PUT("/categories/:slug", controllers.UpdateCategory)
+		admin.DELETE("/categories/:slug", controllers.DeleteCategory)
+		admin.POST("/locations", controllers.CreateLocation)
+		admin.POST("/markets", controllers.CreateMarket)
+		admin.PUT("/markets/:id", controllers.UpdateMarket)
+		admin.POST("/images/gc", controllers.CollectOrphanedImages)
+	}
+
+	// --- Unit registry ---
+	router.GET("/units", controllers.ListUnits)
+	router.GET("/categories", controllers.ListCategories)
+	router.GET("/locations", controllers.ListLocations)
+	router.GET("/locations/autocomplete", controllers.AutocompleteLocations)
+	router.GET("/locations/:id", controllers.GetLocation)
+	router.GET("/markets", controllers.ListMarkets)
+	router.GET("/markets/:id", controllers.GetMarket)
+	router.GET("/markets/:id/prices", controllers.GetMarketPrices)
+
+	// --- Public profiles ---
+	router.GET("/users/:id", controllers.GetPublicProfile)
+
 	// --- Protected routes (JWT required) ---
 	protected := router.Group("/user")
 	protected.Use(middleware.JWTAuthMiddleware())
 	{
 		protected.GET("/profile", controllers.GetProfile)
+		protected.GET("/products", controllers.GetAllProducts)
+		protected.GET("/products/deleted", controllers.GetDeletedProducts)
 	}
 }
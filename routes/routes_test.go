@@ -0,0 +1,93 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter registers the real routes on an engine without the recovery
+// middleware, so a handler panic fails the test instead of becoming a 500
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterRoutes(router)
+	return router
+}
+
+func TestProductWritesRequireAuth(t *testing.T) {
+	router := newTestRouter()
+	const id = "64b7f0c2a1b2c3d4e5f60718"
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/products/"},
+		{http.MethodPut, "/products/" + id},
+		{http.MethodDelete, "/products/" + id},
+		{http.MethodPost, "/products/" + id + "/restore"},
+		{http.MethodPost, "/products/" + id + "/votes"},
+		{http.MethodDelete, "/products/" + id + "/votes"},
+		{http.MethodPost, "/products/" + id + "/report"},
+		{http.MethodPost, "/products/" + id + "/images"},
+		{http.MethodPut, "/products/" + id + "/images/order"},
+		{http.MethodDelete, "/products/" + id + "/images/" + id},
+	}
+	headers := []struct {
+		name  string
+		value string
+	}{
+		{"anonymous", ""},
+		{"malformed header", "Token abc"},
+		{"invalid token", "Bearer not-a-jwt"},
+	}
+
+	for _, tt := range tests {
+		for _, header := range headers {
+			t.Run(tt.method+" "+tt.path+" "+header.name, func(t *testing.T) {
+				req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"name":"rice"}`))
+				req.Header.Set("Content-Type", "application/json")
+				if header.value != "" {
+					req.Header.Set("Authorization", header.value)
+				}
+				rec := httptest.NewRecorder()
+
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("handler panicked: %v", r)
+					}
+				}()
+				router.ServeHTTP(rec, req)
+
+				if rec.Code != http.StatusUnauthorized {
+					t.Fatalf("status = %d, want %d (body %s)", rec.Code, http.StatusUnauthorized, rec.Body.String())
+				}
+				if !strings.Contains(rec.Body.String(), `"error"`) {
+					t.Errorf("body %s has no error message", rec.Body.String())
+				}
+			})
+		}
+	}
+}
+
+func TestPrivilegedRoutesRequireAuth(t *testing.T) {
+	router := newTestRouter()
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodGet, "/moderation/queue"},
+		{http.MethodGet, "/admin/exchange-rates"},
+		{http.MethodPost, "/imports"},
+	} {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(route.method, route.path, nil))
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
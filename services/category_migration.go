@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CategoryMapping struct {
+	Value    string `json:"value"`
+	Slug     string `json:"slug,omitempty"`
+	Products int    `json:"products"`
+	Created  bool   `json:"created,omitempty"`
+}
+
+type CategoryMigrationReport struct {
+	DryRun          bool              `json:"dry_run"`
+	Migrated        []CategoryMapping `json:"migrated"`
+	NeedsReview     []CategoryMapping `json:"needs_review"`
+	Created         int               `json:"created"`
+	ProductsUpdated int               `json:"products_updated"`
+	ItemsUpdated    int               `json:"items_updated"`
+}
+
+// categorySlug turns a free-text category into a slug: "Fruits & Veg" ->
+// "fruits-veg". Empty when nothing is left, or when the value has letters a
+// slug cannot hold ("Épices"), since dropping them would be a guess.
+func categorySlug(value string) string {
+	for _, r := range value {
+		if (unicode.IsLetter(r) || unicode.IsNumber(r)) && r > unicode.MaxASCII {
+			return ""
+		}
+	}
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	return strings.Join(words, "-")
+}
+
+// unsluggedCategoryCounts counts products per category value that is not a
+// known slug
+func unsluggedCategoryCounts(ctx context.Context, known map[string]model.Category) (map[string]int, error) {
+	slugs := make(bson.A, 0, len(known)+2)
+	slugs = append(slugs, nil, "")
+	for slug := range known {
+		slugs = append(slugs, slug)
+	}
+	cursor, err := productCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"category": bson.M{"$nin": slugs}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$category", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Value string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Value] = row.Count
+	}
+	return counts, nil
+}
+
+// 🗂️ MigrateCategoriesToSlugs rewrites free-text categories saved before the
+// taxonomy existed. Each distinct value maps to the category whose name or
+// slug it matches, or else becomes a new top-level category named after it.
+// Values that do not make a clean slug are listed for review. Products and
+// items are rewritten; migrated values no longer match, so it is safe to
+// re-run.
+func MigrateCategoriesToSlugs(dryRun bool) (*CategoryMigrationReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	if err := reloadCategories(); err != nil {
+		return nil, err
+	}
+	categories.mu.RLock()
+	known := make(map[string]model.Category, len(categories.nodes))
+	byName := make(map[string]string, len(categories.nodes))
+	for slug, c := range categories.nodes {
+		known[slug] = c
+		byName[NormalizeAreaName(c.Name)] = slug
+	}
+	categories.mu.RUnlock()
+
+	counts, err := unsluggedCategoryCounts(ctx, known)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	report := &CategoryMigrationReport{DryRun: dryRun, Migrated: []CategoryMapping{}, NeedsReview: []CategoryMapping{}}
+	for _, value := range values {
+		entry := CategoryMapping{Value: value, Products: counts[value]}
+		slug, ok := byName[NormalizeAreaName(value)]
+		if !ok {
+			slug = categorySlug(value)
+		}
+		if slug == "" {
+			report.NeedsReview = append(report.NeedsReview, entry)
+			continue
+		}
+		entry.Slug = slug
+
+		if _, exists := known[slug]; !exists {
+			entry.Created = true
+			report.Created++
+			if !dryRun {
+				created, err := CreateCategory(slug, "", strings.TrimSpace(value), "")
+				if err != nil {
+					return nil, err
+				}
+				known[slug] = *created
+			} else {
+				// Later values with the same slug map to it without creating it again
+				known[slug] = model.Category{Slug: slug}
+			}
+		}
+		report.Migrated = append(report.Migrated, entry)
+
+		if dryRun {
+			report.ProductsUpdated += entry.Products
+			continue
+		}
+		filter := bson.M{"category": value}
+		update := bson.M{"$set": bson.M{"category": slug}}
+		products, err := productCollection.UpdateMany(ctx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+		items, err := itemCollection.UpdateMany(ctx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+		report.ProductsUpdated += int(products.ModifiedCount)
+		report.ItemsUpdated += int(items.ModifiedCount)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,26 @@
+package services
+
+import "testing"
+
+func TestCategorySlug(t *testing.T) {
+	tests := map[string]string{
+		"vegetables":        "vegetables",
+		"Fruits & Veg":      "fruits-veg",
+		"  Grains/Cereals ": "grains-cereals",
+		"Baby Food 2":       "baby-food-2",
+		"--Drinks--":        "drinks",
+		"Épices":            "",
+		"":                  "",
+		"???":               "",
+	}
+
+	for value, want := range tests {
+		got := categorySlug(value)
+		if got != want {
+			t.Errorf("categorySlug(%q) = %q, want %q", value, got, want)
+		}
+		if got != "" && !categorySlugPattern.MatchString(got) {
+			t.Errorf("categorySlug(%q) = %q is not a valid slug", value, got)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var categoryCollection *mongo.Collection
+
+var (
+	ErrUnknownCategory = errors.New("unknown category")
+	ErrCategoryInUse   = errors.New("category still has subcategories or products")
+)
+
+var categorySlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// categoryTree caches the taxonomy for validation and filter expansion,
+// which run on every product write and listing. Admin edits reload it.
+type categoryTree struct {
+	mu    sync.RWMutex
+	nodes map[string]model.Category
+}
+
+var categories = &categoryTree{nodes: map[string]model.Category{}}
+
+func (t *categoryTree) get(slug string) (model.Category, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	c, ok := t.nodes[slug]
+	return c, ok
+}
+
+// subtree is slug followed by every category below it
+func (t *categoryTree) subtree(slug string) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	slugs := []string{slug}
+	for _, c := range t.nodes {
+		for _, ancestor := range c.Ancestors {
+			if ancestor == slug {
+				slugs = append(slugs, c.Slug)
+				break
+			}
+		}
+	}
+	sort.Strings(slugs[1:])
+	return slugs
+}
+
+func InitCategoryService() {
+	categoryCollection = config.DB.Collection("categories")
+	ensureIndexes(categoryCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "ancestors", Value: 1}}},
+		{Keys: bson.D{{Key: "parent", Value: 1}, {Key: "name", Value: 1}}},
+	})
+	if err := reloadCategories(); err != nil {
+		log.Printf("⚠️ Failed to load categories: %v", err)
+	}
+}
+
+func reloadCategories() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := categoryCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var all []model.Category
+	if err := cursor.All(ctx, &all); err != nil {
+		return err
+	}
+
+	nodes := make(map[string]model.Category, len(all))
+	for _, c := range all {
+		nodes[c.Slug] = c
+	}
+	categories.mu.Lock()
+	categories.nodes = nodes
+	categories.mu.Unlock()
+	return nil
+}
+
+// IsKnownCategory backs the "category" binding validator. An empty value is
+// allowed; uncategorized listings are valid.
+func IsKnownCategory(slug string) bool {
+	if slug == "" {
+		return true
+	}
+	_, ok := categories.get(slug)
+	return ok
+}
+
+// expandCategories replaces each category with its whole subtree so
+// filtering by a parent includes its children. Unknown values are kept
+// as-is for listings saved before the taxonomy existed.
+func expandCategories(slugs []string) []string {
+	seen := map[string]bool{}
+	var expanded []string
+	for _, slug := range slugs {
+		for _, s := range categories.subtree(slug) {
+			if !seen[s] {
+				seen[s] = true
+				expanded = append(expanded, s)
+			}
+		}
+	}
+	return expanded
+}
+
+// CategoryNode is a category with its children, for tree responses
+type CategoryNode struct {
+	model.Category
+	Children []*CategoryNode `json:"children"`
+}
+
+// 🗂️ The taxonomy as a tree, siblings ordered by name
+func GetCategoryTree() []*CategoryNode {
+	categories.mu.RLock()
+	byParent := map[string][]*CategoryNode{}
+	for _, c := range categories.nodes {
+		byParent[c.Parent] = append(byParent[c.Parent], &CategoryNode{Category: c, Children: []*CategoryNode{}})
+	}
+	categories.mu.RUnlock()
+
+	var attach func(parent string) []*CategoryNode
+	attach = func(parent string) []*CategoryNode {
+		nodes := byParent[parent]
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+		for _, n := range nodes {
+			n.Children = append(n.Children, attach(n.Slug)...)
+		}
+		if nodes == nil {
+			return []*CategoryNode{}
+		}
+		return nodes
+	}
+	return attach("")
+}
+
+// ✅ Add a category under parent ("" for a top-level category)
+func CreateCategory(slug, parent, name, icon string) (*model.Category, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	slug = strings.TrimSpace(slug)
+	name = strings.TrimSpace(name)
+	if !categorySlugPattern.MatchString(slug) {
+		return nil, &FilterError{Err: errors.New("slug must be lowercase letters, digits and hyphens")}
+	}
+	if name == "" {
+		return nil, &FilterError{Err: errors.New("name is required")}
+	}
+
+	ancestors := []string{}
+	if parent != "" {
+		p, ok := categories.get(parent)
+		if !ok {
+			return nil, &FilterError{Err: fmt.Errorf("%w: parent %q", ErrUnknownCategory, parent)}
+		}
+		ancestors = append(append(ancestors, p.Ancestors...), p.Slug)
+	}
+
+	now := time.Now()
+	category := model.Category{
+		Slug:      slug,
+		Parent:    parent,
+		Ancestors: ancestors,
+		Name:      name,
+		Icon:      strings.TrimSpace(icon),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := categoryCollection.InsertOne(ctx, category); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, &FilterError{Err: fmt.Errorf("category %q already exists", slug)}
+		}
+		return nil, err
+	}
+	if err := reloadCategories(); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// CategoryUpdate holds the fields to change; nil leaves a field as is.
+// Parent "" moves the category to the top level.
+type CategoryUpdate struct {
+	Name   *string
+	Icon   *string
+	Parent *string
+}
+
+// ✏️ Rename, re-icon or move a category. Moving rewrites the ancestors of
+// the whole subtree; a category cannot move under its own descendant.
+func UpdateCategory(slug string, update CategoryUpdate) (*model.Category, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	current, ok := categories.get(slug)
+	if !ok {
+		return nil, ErrUnknownCategory
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" {
+			return nil, &FilterError{Err: errors.New("name must not be empty")}
+		}
+		set["name"] = name
+	}
+	if update.Icon != nil {
+		set["icon"] = strings.TrimSpace(*update.Icon)
+	}
+
+	var subtreeWrites []mongo.WriteModel
+	if update.Parent != nil && *update.Parent != current.Parent {
+		newParent := *update.Parent
+		ancestors := []string{}
+		if newParent != "" {
+			p, ok := categories.get(newParent)
+			if !ok {
+				return nil, &FilterError{Err: fmt.Errorf("%w: parent %q", ErrUnknownCategory, newParent)}
+			}
+			for _, s := range categories.subtree(slug) {
+				if s == newParent {
+					return nil, &FilterError{Err: errors.New("a category cannot be moved under itself or its subcategories")}
+				}
+			}
+			ancestors = append(append(ancestors, p.Ancestors...), p.Slug)
+		}
+		set["parent"] = newParent
+		set["ancestors"] = ancestors
+
+		// Descendants keep their path below slug and take the new prefix
+		for _, s := range categories.subtree(slug)[1:] {
+			d, _ := categories.get(s)
+			below := d.Ancestors[len(current.Ancestors):]
+			path := append(append([]string{}, ancestors...), below...)
+			subtreeWrites = append(subtreeWrites, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": s}).
+				SetUpdate(bson.M{"$set": bson.M{"ancestors": path}}))
+		}
+	}
+
+	if _, err := categoryCollection.UpdateOne(ctx, bson.M{"_id": slug}, bson.M{"$set": set}); err != nil {
+		return nil, err
+	}
+	if len(subtreeWrites) > 0 {
+		if _, err := categoryCollection.BulkWrite(ctx, subtreeWrites); err != nil {
+			return nil, err
+		}
+	}
+	if err := reloadCategories(); err != nil {
+		return nil, err
+	}
+
+	updated, _ := categories.get(slug)
+	return &updated, nil
+}
+
+// 🗑️ Delete a leaf category that no live product uses
+func DeleteCategory(slug string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, ok := categories.get(slug); !ok {
+		return ErrUnknownCategory
+	}
+	if len(categories.subtree(slug)) > 1 {
+		return ErrCategoryInUse
+	}
+	inUse, err := productCollection.CountDocuments(ctx, bson.M{"category": slug, "deleted_at": nil})
+	if err != nil {
+		return err
+	}
+	if inUse > 0 {
+		return ErrCategoryInUse
+	}
+
+	if _, err := categoryCollection.DeleteOne(ctx, bson.M{"_id": slug}); err != nil {
+		return err
+	}
+	return reloadCategories()
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"strconv"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrUploadNotFound         = errors.New("upload not found, expired or already used")
+	ErrUploadMissing          = errors.New("the file has not been uploaded yet")
+	ErrInvalidUploadSignature = errors.New("invalid or expired upload signature")
+)
+
+// rawUploadExt marks direct uploads in the local store before they are
+// processed
+const rawUploadExt = ".raw"
+
+var uploadCollection *mongo.Collection
+
+// UploadTarget tells a client where and how to upload a file directly.
+// Fields, when set, go into the multipart form next to the "file" field;
+// otherwise the file is the request body.
+type UploadTarget struct {
+	UploadID  string            `json:"upload_id"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// ✅ Initialize upload slots; records are dropped a day after they expire
+func InitUploadService() {
+	uploadCollection = config.DB.Collection("uploads")
+	ensureIndexes(uploadCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(24 * 60 * 60)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}}},
+	})
+}
+
+// ✍️ Open an upload slot for userID and sign a direct upload to it
+func SignUpload(userID primitive.ObjectID) (*UploadTarget, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	upload := model.Upload{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Status:    model.UploadPending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(config.UploadURLTTL),
+	}
+	upload.Key = uploadFolder + "/" + upload.ID.Hex()
+
+	target, err := imageStore.SignUpload(upload.Key, upload.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uploadCollection.InsertOne(ctx, upload); err != nil {
+		return nil, err
+	}
+	target.UploadID = upload.ID.Hex()
+	target.ExpiresAt = upload.ExpiresAt
+	return &target, nil
+}
+
+// signLocalUpload is the HMAC authorising a local direct upload of key
+// until the unix time exp
+func signLocalUpload(key, exp string) string {
+	mac := hmac.New(sha256.New, config.UploadSigningKey)
+	mac.Write([]byte(key + "\n" + exp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// 📥 Store a direct upload sent to the local image store's signed URL. The
+// file is kept raw; it is validated when a product claims it.
+func ReceiveLocalUpload(uploadID, exp, signature string, r io.Reader) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	store, ok := imageStore.(*localImageStore)
+	if !ok {
+		return ErrUploadNotFound
+	}
+	id, err := primitive.ObjectIDFromHex(uploadID)
+	if err != nil {
+		return ErrUploadNotFound
+	}
+	key := uploadFolder + "/" + id.Hex()
+	if err := verifyLocalUpload(key, exp, signature, time.Now()); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, config.MaxImageBytes+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > config.MaxImageBytes {
+		return fmt.Errorf("%w: the limit is %d MB", ErrImageTooLarge, config.MaxImageBytes>>20)
+	}
+
+	count, err := uploadCollection.CountDocuments(ctx, bson.M{"_id": id, "status": model.UploadPending})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrUploadNotFound
+	}
+	_, err = store.Put(ctx, key, rawUploadExt, data)
+	return err
+}
+
+// verifyLocalUpload checks the signature and expiry of a local upload URL
+func verifyLocalUpload(key, exp, signature string, now time.Time) error {
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(signLocalUpload(key, exp))) || now.Unix() > expires {
+		return ErrInvalidUploadSignature
+	}
+	return nil
+}
+
+// 📎 Claim direct uploads for a product. Each ID must be a live slot
+// of userID whose file arrived; the files are validated and stored like
+// form uploads. On any failure the slots are released for a retry.
+func ClaimUploads(userID primitive.ObjectID, uploadIDs []string) ([]StoredImage, error) {
+	if len(uploadIDs) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	now := time.Now()
+	var claimed []model.Upload
+	release := func() {
+		for _, upload := range claimed {
+			_, _ = uploadCollection.UpdateOne(ctx, bson.M{"_id": upload.ID}, bson.M{
+				"$set":   bson.M{"status": model.UploadPending},
+				"$unset": bson.M{"claimed_at": ""},
+			})
+		}
+	}
+
+	for _, raw := range uploadIDs {
+		id, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("%w: %s", ErrUploadNotFound, raw)
+		}
+		var upload model.Upload
+		err = uploadCollection.FindOneAndUpdate(ctx,
+			bson.M{"_id": id, "user_id": userID, "status": model.UploadPending, "expires_at": bson.M{"$gt": now}},
+			bson.M{"$set": bson.M{"status": model.UploadClaimed, "claimed_at": now}},
+		).Decode(&upload)
+		if err != nil {
+			release()
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, fmt.Errorf("%w: %s", ErrUploadNotFound, raw)
+			}
+			return nil, err
+		}
+		claimed = append(claimed, upload)
+	}
+
+	stored := make([]StoredImage, 0, len(claimed))
+	for _, upload := range claimed {
+		image, err := processUpload(ctx, upload)
+		if err != nil {
+			release()
+			DeleteUnusedImages(storedURLs(stored))
+			return nil, fmt.Errorf("upload %s: %w", upload.ID.Hex(), err)
+		}
+		stored = append(stored, *image)
+	}
+
+	// The raw files are only needed until every upload went through
+	for _, upload := range claimed {
+		if err := imageStore.Delete(ctx, upload.Key); err != nil {
+			log.Printf("⚠️ Failed to delete raw upload %s: %v", upload.Key, err)
+		}
+	}
+	return stored, nil
+}
+
+func processUpload(ctx context.Context, upload model.Upload) (*StoredImage, error) {
+	file, err := imageStore.Open(ctx, upload.Key)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return UploadImage(file)
+}
+
+// 🖼️ Gather a product's new images from form files and claimed direct
+// uploads, in that order, cleaning up if either part fails
+func CollectImages(userID primitive.ObjectID, headers []*multipart.FileHeader, uploadIDs []string) ([]StoredImage, error) {
+	stored, err := UploadImages(headers)
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := ClaimUploads(userID, uploadIDs)
+	if err != nil {
+		DeleteUnusedImages(storedURLs(stored))
+		return nil, err
+	}
+	return append(stored, claimed...), nil
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func withUploadSigningKey(t *testing.T) {
+	saved := config.UploadSigningKey
+	config.UploadSigningKey = []byte("test-secret")
+	t.Cleanup(func() { config.UploadSigningKey = saved })
+}
+
+func newLocalImageStore(t *testing.T) *localImageStore {
+	store := &localImageStore{dir: t.TempDir(), uploadDir: t.TempDir(), baseURL: "/uploads"}
+	saved := imageStore
+	imageStore = store
+	t.Cleanup(func() { imageStore = saved })
+	return store
+}
+
+func TestVerifyLocalUpload(t *testing.T) {
+	withUploadSigningKey(t)
+	now := time.Now()
+	key := uploadFolder + "/" + primitive.NewObjectID().Hex()
+	exp := strconv.FormatInt(now.Add(time.Minute).Unix(), 10)
+	signature := signLocalUpload(key, exp)
+	past := strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name            string
+		key, exp, sig   string
+		wantSignatureOK bool
+	}{
+		{"valid", key, exp, signature, true},
+		{"expired", key, past, signLocalUpload(key, past), false},
+		{"tampered signature", key, exp, strings.Repeat("0", len(signature)), false},
+		{"later expiry than signed", key, strconv.FormatInt(now.Add(time.Hour).Unix(), 10), signature, false},
+		{"another upload's key", uploadFolder + "/" + primitive.NewObjectID().Hex(), exp, signature, false},
+		{"missing expiry", key, "", signature, false},
+		{"missing signature", key, exp, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyLocalUpload(tt.key, tt.exp, tt.sig, now)
+			if tt.wantSignatureOK && err != nil {
+				t.Errorf("err = %v, want nil", err)
+			}
+			if !tt.wantSignatureOK && !errors.Is(err, ErrInvalidUploadSignature) {
+				t.Errorf("err = %v, want ErrInvalidUploadSignature", err)
+			}
+		})
+	}
+}
+
+func TestReceiveLocalUploadRejects(t *testing.T) {
+	withUploadSigningKey(t)
+	withMaxImageBytes(t, 16)
+	store := newLocalImageStore(t)
+
+	id := primitive.NewObjectID().Hex()
+	exp := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+	signature := signLocalUpload(uploadFolder+"/"+id, exp)
+
+	tests := []struct {
+		name         string
+		id, exp, sig string
+		body         []byte
+		want         error
+	}{
+		{"invalid id", "not-an-id", exp, signature, []byte("x"), ErrUploadNotFound},
+		{"bad signature", id, exp, "bad", []byte("x"), ErrInvalidUploadSignature},
+		{"too large", id, exp, signature, bytes.Repeat([]byte("x"), 17), ErrImageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ReceiveLocalUpload(tt.id, tt.exp, tt.sig, bytes.NewReader(tt.body))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	objects, err := store.List(context.Background(), uploadFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 0 {
+		t.Errorf("rejected uploads left %+v behind", objects)
+	}
+}
+
+func TestLocalImageStoreKeepsRawUploadsPrivate(t *testing.T) {
+	store := newLocalImageStore(t)
+	ctx := context.Background()
+	rawKey := uploadFolder + "/" + primitive.NewObjectID().Hex()
+	imageKey := imageFolder + "/abc123"
+
+	if _, err := store.Put(ctx, rawKey, rawUploadExt, []byte("raw")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Put(ctx, imageKey, ".jpg", []byte("jpg")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing under the served directory belongs to a raw upload
+	err := filepath.WalkDir(store.dir, func(name string, entry os.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && strings.HasSuffix(name, rawUploadExt) {
+			t.Errorf("raw upload %s is inside the served directory", name)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]string{rawKey: store.uploadDir, imageKey: store.dir} {
+		if exists, err := store.Exists(ctx, key); err != nil || !exists {
+			t.Errorf("Exists(%s) = %v, %v", key, exists, err)
+		}
+		objects, err := store.List(ctx, key[:strings.LastIndex(key, "/")])
+		if err != nil || len(objects) != 1 || objects[0].Key != key {
+			t.Errorf("List for %s = %+v, %v", key, objects, err)
+		}
+		if target, _ := store.path(key, ".x"); !strings.HasPrefix(target, want) {
+			t.Errorf("%s is stored at %s, want below %s", key, target, want)
+		}
+	}
+
+	for _, key := range []string{"crowd_market/other/x", uploadFolder + "/../products/x", uploadFolder, "../" + imageKey} {
+		if _, err := store.Put(ctx, key, ".jpg", []byte("x")); err == nil {
+			t.Errorf("Put accepted key %q", key)
+		}
+	}
+}
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		dir, parent string
+		want        bool
+	}{
+		{"uploads", "uploads", true},
+		{"uploads/incoming", "uploads", true},
+		{"./uploads/../uploads/x", "uploads", true},
+		{"incoming", "uploads", false},
+		{"uploads-incoming", "uploads", false},
+		{"../uploads", "uploads", false},
+	}
+
+	for _, tt := range tests {
+		if got := within(tt.dir, tt.parent); got != tt.want {
+			t.Errorf("within(%q, %q) = %v, want %v", tt.dir, tt.parent, got, tt.want)
+		}
+	}
+}
+
+// TestClaimUploadsReleasesOnFailure needs a MongoDB server in TEST_MONGO_URI
+func TestClaimUploadsReleasesOnFailure(t *testing.T) {
+	testDatabase(t)
+	withMaxImageBytes(t, 1<<20)
+	store := newMemImageStore(t)
+
+	ctx := context.Background()
+	userID := primitive.NewObjectID()
+	newUpload := func(owner primitive.ObjectID, withFile bool) model.Upload {
+		upload := model.Upload{
+			ID:        primitive.NewObjectID(),
+			UserID:    owner,
+			Status:    model.UploadPending,
+			CreatedAt: time.Now(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		upload.Key = uploadFolder + "/" + upload.ID.Hex()
+		if _, err := uploadCollection.InsertOne(ctx, upload); err != nil {
+			t.Fatal(err)
+		}
+		if withFile {
+			store.files[upload.Key] = encodeTestPNG(t, testImage(50, 40))
+		}
+		return upload
+	}
+
+	tests := []struct {
+		name    string
+		uploads func() []model.Upload
+		want    error
+	}{
+		{"file never arrived", func() []model.Upload {
+			return []model.Upload{newUpload(userID, true), newUpload(userID, false)}
+		}, ErrUploadMissing},
+		{"someone else's upload", func() []model.Upload {
+			return []model.Upload{newUpload(userID, true), newUpload(primitive.NewObjectID(), true)}
+		}, ErrUploadNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploads := tt.uploads()
+			ids := make([]string, len(uploads))
+			for i, upload := range uploads {
+				ids[i] = upload.ID.Hex()
+			}
+
+			if _, err := ClaimUploads(userID, ids); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			for _, upload := range uploads {
+				var got model.Upload
+				if err := uploadCollection.FindOne(ctx, bson.M{"_id": upload.ID}).Decode(&got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Status != model.UploadPending || got.ClaimedAt != nil {
+					t.Errorf("upload %s = %s claimed at %v, want it released", upload.ID.Hex(), got.Status, got.ClaimedAt)
+				}
+			}
+			for key := range store.files {
+				if strings.HasPrefix(key, imageFolder+"/") {
+					t.Errorf("processed image %s was kept after the claim failed", key)
+				}
+			}
+		})
+	}
+
+	// A complete claim goes through and drops the raw file
+	upload := newUpload(userID, true)
+	stored, err := ClaimUploads(userID, []string{upload.ID.Hex()})
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("ClaimUploads = %+v, %v", stored, err)
+	}
+	if _, ok := store.files[upload.Key]; ok {
+		t.Error("the raw upload was kept after it was claimed")
+	}
+}
@@ -0,0 +1,435 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var exchangeRateCollection *mongo.Collection
+
+var (
+	ErrNoExchangeRate   = errors.New("no exchange rate")
+	ErrRateNotFound     = errors.New("exchange rate not found")
+	ErrInvalidRate      = errors.New("rate must be a positive number")
+	ErrSameCurrencyPair = errors.New("base and quote must differ")
+)
+
+func InitExchangeRateService() {
+	exchangeRateCollection = config.DB.Collection("exchange_rates")
+	ensureIndexes(exchangeRateCollection, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "base", Value: 1}, {Key: "quote", Value: 1}, {Key: "effective_date", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "quote", Value: 1}}},
+	})
+}
+
+// ExchangeRateInput is a rate as entered by an admin or read from CSV
+type ExchangeRateInput struct {
+	Base          string
+	Quote         string
+	Rate          float64
+	EffectiveDate string // YYYY-MM-DD
+	Source        string
+}
+
+// normalize validates the input and returns the rate it describes
+func (in ExchangeRateInput) normalize() (model.ExchangeRate, error) {
+	base, err := model.NormalizeCurrency(in.Base)
+	if err != nil {
+		return model.ExchangeRate{}, err
+	}
+	quote, err := model.NormalizeCurrency(in.Quote)
+	if err != nil {
+		return model.ExchangeRate{}, err
+	}
+	if base == quote {
+		return model.ExchangeRate{}, ErrSameCurrencyPair
+	}
+	if in.Rate <= 0 || math.IsNaN(in.Rate) || math.IsInf(in.Rate, 0) {
+		return model.ExchangeRate{}, ErrInvalidRate
+	}
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(in.EffectiveDate))
+	if err != nil {
+		return model.ExchangeRate{}, errors.New("effective_date must be YYYY-MM-DD")
+	}
+	return model.ExchangeRate{
+		Base:          base,
+		Quote:         quote,
+		Rate:          in.Rate,
+		EffectiveDate: date,
+		Source:        strings.TrimSpace(in.Source),
+	}, nil
+}
+
+// upsertExchangeRate stores a rate, replacing any rate for the same pair and
+// day. created reports whether a new document was inserted.
+func upsertExchangeRate(ctx context.Context, rate model.ExchangeRate, actorID primitive.ObjectID) (*model.ExchangeRate, bool, error) {
+	now := time.Now()
+	key := bson.M{"base": rate.Base, "quote": rate.Quote, "effective_date": rate.EffectiveDate}
+	result, err := exchangeRateCollection.UpdateOne(ctx, key,
+		bson.M{
+			"$set":         bson.M{"rate": rate.Rate, "source": rate.Source, "created_by": actorID, "updated_at": now},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var saved model.ExchangeRate
+	if err := exchangeRateCollection.FindOne(ctx, key).Decode(&saved); err != nil {
+		return nil, false, err
+	}
+	return &saved, result.UpsertedCount > 0, nil
+}
+
+// 💱 Create (or replace) the rate for a pair on a date
+func SaveExchangeRate(in ExchangeRateInput, actorID string) (*model.ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rate, err := in.normalize()
+	if err != nil {
+		return nil, &FilterError{Err: err}
+	}
+	actorObjID, err := toObjectID(actorID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	saved, _, err := upsertExchangeRate(ctx, rate, actorObjID)
+	return saved, err
+}
+
+// 💱 Change the rate value of an existing entry
+func UpdateExchangeRate(id string, rate float64, source string) (*model.ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := toObjectID(id)
+	if err != nil {
+		return nil, ErrRateNotFound
+	}
+	if rate <= 0 || math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return nil, &FilterError{Err: ErrInvalidRate}
+	}
+
+	set := bson.M{"rate": rate, "updated_at": time.Now()}
+	if source = strings.TrimSpace(source); source != "" {
+		set["source"] = source
+	}
+
+	var updated model.ExchangeRate
+	err = exchangeRateCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objID},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrRateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// 🗑️ Remove an exchange rate
+func DeleteExchangeRate(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := toObjectID(id)
+	if err != nil {
+		return ErrRateNotFound
+	}
+	result, err := exchangeRateCollection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrRateNotFound
+	}
+	return nil
+}
+
+// 📋 Rates, newest first, optionally for one currency (as base or quote)
+func ListExchangeRates(currency string) ([]model.ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if currency != "" {
+		code, err := model.NormalizeCurrency(currency)
+		if err != nil {
+			return nil, &FilterError{Err: err}
+		}
+		filter["$or"] = bson.A{bson.M{"base": code}, bson.M{"quote": code}}
+	}
+
+	opts := options.Find().SetSort(bson.D{
+		{Key: "effective_date", Value: -1},
+		{Key: "base", Value: 1},
+		{Key: "quote", Value: 1},
+	})
+	cursor, err := exchangeRateCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rates := []model.ExchangeRate{}
+	if err := cursor.All(ctx, &rates); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+type RateImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type RateImportReport struct {
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Errors  []RateImportError `json:"errors"`
+}
+
+// 📥 Import rates from CSV with a header row of base,quote,rate,date and an
+// optional source column. Valid rows are saved even when others fail; each
+// failure is reported with its line number.
+func ImportExchangeRatesCSV(r io.Reader, actorID string) (*RateImportReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	actorObjID, err := toObjectID(actorID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, &FilterError{Err: errors.New("CSV is empty or unreadable")}
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"base", "quote", "rate", "date"} {
+		if _, ok := columns[required]; !ok {
+			return nil, &FilterError{Err: fmt.Errorf("CSV header must include %q", required)}
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	report := &RateImportReport{Errors: []RateImportError{}}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return report, err
+			}
+			report.Errors = append(report.Errors, RateImportError{Line: parseErr.Line, Error: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		value, err := strconv.ParseFloat(field(row, "rate"), 64)
+		if err != nil {
+			report.Errors = append(report.Errors, RateImportError{Line: line, Error: ErrInvalidRate.Error()})
+			continue
+		}
+		rate, err := ExchangeRateInput{
+			Base:          field(row, "base"),
+			Quote:         field(row, "quote"),
+			Rate:          value,
+			EffectiveDate: field(row, "date"),
+			Source:        field(row, "source"),
+		}.normalize()
+		if err != nil {
+			report.Errors = append(report.Errors, RateImportError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		_, created, err := upsertExchangeRate(ctx, rate, actorObjID)
+		if err != nil {
+			return report, err
+		}
+		if created {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+	}
+	return report, nil
+}
+
+// --- Conversion ---
+
+// AppliedRate records a rate used to convert prices in a response
+type AppliedRate struct {
+	From          string             `json:"from"`
+	To            string             `json:"to"`
+	Rate          float64            `json:"rate"`
+	EffectiveDate time.Time          `json:"effective_date"`
+	RateID        primitive.ObjectID `json:"rate_id"`
+	// Inverted is set when the stored rate is for the opposite pair
+	Inverted bool `json:"inverted,omitempty"`
+}
+
+// CurrencyConverter converts prices into one target currency using the rate
+// in effect on each price's date. Rates involving the target are loaded once
+// and every rate used is remembered for the response.
+type CurrencyConverter struct {
+	Target string
+	rates  map[string][]model.ExchangeRate // "BASE/QUOTE", newest first
+	used   map[primitive.ObjectID]AppliedRate
+}
+
+func NewCurrencyConverter(ctx context.Context, target string) (*CurrencyConverter, error) {
+	target, err := model.NormalizeCurrency(target)
+	if err != nil {
+		return nil, &FilterError{Err: err}
+	}
+
+	cursor, err := exchangeRateCollection.Find(ctx,
+		bson.M{"$or": bson.A{bson.M{"base": target}, bson.M{"quote": target}}},
+		options.Find().SetSort(bson.D{{Key: "effective_date", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var rates []model.ExchangeRate
+	if err := cursor.All(ctx, &rates); err != nil {
+		return nil, err
+	}
+
+	cv := &CurrencyConverter{
+		Target: target,
+		rates:  map[string][]model.ExchangeRate{},
+		used:   map[primitive.ObjectID]AppliedRate{},
+	}
+	for _, r := range rates {
+		key := r.Base + "/" + r.Quote
+		cv.rates[key] = append(cv.rates[key], r)
+	}
+	return cv, nil
+}
+
+// rateOn is the newest rate for the pair effective on or before at
+func (cv *CurrencyConverter) rateOn(base, quote string, at time.Time) (model.ExchangeRate, bool) {
+	for _, r := range cv.rates[base+"/"+quote] {
+		if !r.EffectiveDate.After(at) {
+			return r, true
+		}
+	}
+	return model.ExchangeRate{}, false
+}
+
+// Convert expresses m in the target currency at the rate in effect at the
+// given time, rounded to the target's minor unit.
+func (cv *CurrencyConverter) Convert(m model.Money, at time.Time) (model.Money, AppliedRate, error) {
+	if m.Currency == cv.Target {
+		return m, AppliedRate{}, nil
+	}
+
+	applied := AppliedRate{From: m.Currency, To: cv.Target}
+	if r, ok := cv.rateOn(m.Currency, cv.Target, at); ok {
+		applied.Rate, applied.EffectiveDate, applied.RateID = r.Rate, r.EffectiveDate, r.ID
+	} else if r, ok := cv.rateOn(cv.Target, m.Currency, at); ok {
+		applied.Rate, applied.EffectiveDate, applied.RateID = 1/r.Rate, r.EffectiveDate, r.ID
+		applied.Inverted = true
+	} else {
+		return model.Money{}, AppliedRate{}, fmt.Errorf("%w from %s to %s on %s",
+			ErrNoExchangeRate, m.Currency, cv.Target, at.UTC().Format("2006-01-02"))
+	}
+
+	fromExp, _ := model.CurrencyExponent(m.Currency)
+	toExp, _ := model.CurrencyExponent(cv.Target)
+	amount := math.Round(float64(m.Amount) * applied.Rate * math.Pow10(toExp-fromExp))
+
+	cv.used[applied.RateID] = applied
+	return model.Money{Amount: int64(amount), Currency: cv.Target}, applied, nil
+}
+
+// ConvertProducts sets ConvertedPrice on each product using the rate in
+// effect when it was reported. Products with no applicable rate are left
+// unconverted.
+func (cv *CurrencyConverter) ConvertProducts(products []*model.Product) {
+	for _, p := range products {
+		at := time.Now()
+		if p.CreatedAt != nil {
+			at = *p.CreatedAt
+		}
+		converted, applied, err := cv.Convert(p.Price, at)
+		if err != nil {
+			continue
+		}
+		rate := 1.0
+		rateDate := at
+		if applied.RateID != primitive.NilObjectID {
+			rate, rateDate = applied.Rate, applied.EffectiveDate
+		}
+		p.Converted = &model.ConvertedPrice{Money: converted, Rate: rate, RateDate: rateDate}
+	}
+}
+
+// RatesUsed lists every rate applied so far
+func (cv *CurrencyConverter) RatesUsed() []AppliedRate {
+	used := make([]AppliedRate, 0, len(cv.used))
+	for _, r := range cv.used {
+		used = append(used, r)
+	}
+	sort.Slice(used, func(i, j int) bool {
+		if used[i].From != used[j].From {
+			return used[i].From < used[j].From
+		}
+		return used[i].EffectiveDate.Before(used[j].EffectiveDate)
+	})
+	return used
+}
+
+// convertReports rewrites report prices into the converter's currency
+func (cv *CurrencyConverter) convertReports(reports []model.PriceReport) error {
+	for i := range reports {
+		converted, _, err := cv.Convert(reports[i].Price, reports[i].ObservedAt)
+		if err != nil {
+			return err
+		}
+		reports[i].Price = converted
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	DefaultNearbyRadiusKm = 5.0
+	MaxNearbyRadiusKm     = 100.0
+)
+
+type NearbyQuery struct {
+	Lat      float64
+	Lng      float64
+	RadiusKm float64
+	Filter   ProductFilter
+	Limit    int
+}
+
+type NearbyProduct struct {
+	Product    model.Product `json:"product"`
+	DistanceKm float64       `json:"distance_km"`
+}
+
+// ValidateCoordinates checks a latitude/longitude pair. The ranges are
+// written so NaN fails them too.
+func ValidateCoordinates(lat, lng float64) error {
+	if !(lat >= -90 && lat <= 90) {
+		return errors.New("lat must be between -90 and 90")
+	}
+	if !(lng >= -180 && lng <= 180) {
+		return errors.New("lng must be between -180 and 180")
+	}
+	return nil
+}
+
+// parseFiniteFloat parses a number, rejecting NaN and infinities that
+// strconv accepts but no range check catches
+func parseFiniteFloat(raw string) (float64, error) {
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, strconv.ErrSyntax
+	}
+	return value, nil
+}
+
+// 📍 Products within a radius, nearest first. Area-only reports without a
+// location are simply not part of the result.
+func FindNearbyProducts(q NearbyQuery) ([]NearbyProduct, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ValidateCoordinates(q.Lat, q.Lng); err != nil {
+		return nil, &FilterError{Err: err}
+	}
+	if q.RadiusKm == 0 {
+		q.RadiusKm = DefaultNearbyRadiusKm
+	}
+	if !(q.RadiusKm > 0 && q.RadiusKm <= MaxNearbyRadiusKm) {
+		return nil, &FilterError{Err: fmt.Errorf("radius_km must be between 0 and %g", MaxNearbyRadiusKm)}
+	}
+	if err := q.Filter.Validate(); err != nil {
+		return nil, &FilterError{Err: err}
+	}
+	if q.Limit <= 0 {
+		q.Limit = config.ProductPageSize
+	}
+	if q.Limit > config.MaxProductPageSize {
+		q.Limit = config.MaxProductPageSize
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.M{
+			"near":          model.NewGeoPoint(q.Lat, q.Lng),
+			"distanceField": "distance_m",
+			"maxDistance":   q.RadiusKm * 1000,
+			"spherical":     true,
+			"query":         q.Filter.toBSON(),
+		}}},
+		{{Key: "$limit", Value: q.Limit}},
+	}
+
+	cursor, err := productCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []NearbyProduct{}
+	for cursor.Next(ctx) {
+		var doc struct {
+			model.Product `bson:",inline"`
+			DistanceM     float64 `bson:"distance_m"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		results = append(results, NearbyProduct{Product: doc.Product, DistanceKm: doc.DistanceM / 1000})
+	}
+
+	return results, cursor.Err()
+}
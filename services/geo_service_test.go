@@ -0,0 +1,55 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestValidateCoordinates(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+
+	tests := []struct {
+		name     string
+		lat, lng float64
+		wantErr  bool
+	}{
+		{"lagos", 6.5244, 3.3792, false},
+		{"corners", -90, 180, false},
+		{"lat out of range", 91, 0, true},
+		{"lng out of range", 0, -181, true},
+		{"lat NaN", nan, 0, true},
+		{"lng NaN", 0, nan, true},
+		{"lat Inf", inf, 0, true},
+		{"lng -Inf", 0, -inf, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateCoordinates(tt.lat, tt.lng); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCoordinates(%g, %g) = %v, want error %v", tt.lat, tt.lng, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFindNearbyProductsRejectsBadInput(t *testing.T) {
+	nan := math.NaN()
+	confidence := nan
+
+	// Each is rejected before any query runs
+	for name, q := range map[string]NearbyQuery{
+		"NaN lat":            {Lat: nan, Lng: 3.3},
+		"NaN radius":         {Lat: 6.5, Lng: 3.3, RadiusKm: nan},
+		"infinite radius":    {Lat: 6.5, Lng: 3.3, RadiusKm: math.Inf(1)},
+		"negative radius":    {Lat: 6.5, Lng: 3.3, RadiusKm: -1},
+		"NaN min_confidence": {Lat: 6.5, Lng: 3.3, Filter: ProductFilter{MinConfidence: &confidence}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var filterErr *FilterError
+			if _, err := FindNearbyProducts(q); !errors.As(err, &filterErr) {
+				t.Fatalf("err = %v, want a FilterError", err)
+			}
+		})
+	}
+}
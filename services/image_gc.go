@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OrphanedImage is a stored file nothing references any more
+type OrphanedImage struct {
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ImageGCReport summarises one run of the orphaned image collector
+type ImageGCReport struct {
+	DryRun     bool            `json:"dry_run"`
+	Grace      string          `json:"grace"`
+	Scanned    int             `json:"scanned"`
+	Referenced int             `json:"referenced"`
+	TooRecent  int             `json:"too_recent"`
+	Orphaned   []OrphanedImage `json:"orphaned"`
+	Deleted    int             `json:"deleted"`
+	Failed     []string        `json:"failed,omitempty"`
+}
+
+// baseImageKey strips a variant suffix, so a thumbnail is kept alive by its
+// original
+func baseImageKey(key string) string {
+	for _, variant := range imageVariants {
+		if trimmed, ok := strings.CutSuffix(key, variant.suffix); ok {
+			return trimmed
+		}
+	}
+	return key
+}
+
+// 🧹 Delete stored images that no product references and that are older
+// than the grace period. Product images and raw direct uploads without a
+// pending slot are both collected. Soft-deleted products still count as
+// references; their images go when the product is purged. With dryRun the
+// report lists what would be deleted without deleting it.
+func CollectOrphanedImages(dryRun bool) (*ImageGCReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	report := &ImageGCReport{DryRun: dryRun, Grace: config.ImageGCGrace.String(), Orphaned: []OrphanedImage{}}
+
+	// List before collecting references: a file stored after the listing is
+	// not considered, and one referenced meanwhile is seen as referenced
+	var objects []StoredObject
+	for _, folder := range []string{imageFolder, uploadFolder} {
+		listed, err := imageStore.List(ctx, folder)
+		if err != nil {
+			return nil, fmt.Errorf("listing %s: %w", folder, err)
+		}
+		objects = append(objects, listed...)
+	}
+	report.Scanned = len(objects)
+
+	referenced, err := referencedImageKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	classifyStoredImages(report, objects, referenced, time.Now().Add(-config.ImageGCGrace))
+	if dryRun {
+		return report, nil
+	}
+
+	for _, orphan := range report.Orphaned {
+		if err := imageStore.Delete(ctx, orphan.Key); err != nil {
+			log.Printf("⚠️ Failed to delete orphaned image %s: %v", orphan.Key, err)
+			report.Failed = append(report.Failed, orphan.Key+": "+err.Error())
+			continue
+		}
+		report.Deleted++
+	}
+	return report, nil
+}
+
+// classifyStoredImages counts each object as referenced, too recent to
+// collect (stored after cutoff) or orphaned
+func classifyStoredImages(report *ImageGCReport, objects []StoredObject, referenced map[string]bool, cutoff time.Time) {
+	for _, object := range objects {
+		switch {
+		case referenced[baseImageKey(object.Key)]:
+			report.Referenced++
+		case object.CreatedAt.After(cutoff):
+			report.TooRecent++
+		default:
+			report.Orphaned = append(report.Orphaned, OrphanedImage{Key: object.Key, CreatedAt: object.CreatedAt})
+		}
+	}
+}
+
+// referencedImageKeys collects the store keys of every product image,
+// deleted products included, and of raw uploads waiting to be claimed
+func referencedImageKeys(ctx context.Context) (map[string]bool, error) {
+	referenced := map[string]bool{}
+	add := func(url string) {
+		if key, ok := imageKeyFromURL(url); ok {
+			referenced[baseImageKey(key)] = true
+		}
+	}
+
+	cursor, err := productCollection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"image_url": 1, "images": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var p model.Product
+		if err := cursor.Decode(&p); err != nil {
+			return nil, err
+		}
+		add(p.ImageURL)
+		for _, image := range p.Images {
+			add(image.URL)
+			add(image.Thumbnail)
+			add(image.Medium)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	uploads, err := uploadCollection.Find(ctx, bson.M{"status": model.UploadPending, "expires_at": bson.M{"$gt": time.Now()}}, options.Find().SetProjection(bson.M{"key": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer uploads.Close(ctx)
+	for uploads.Next(ctx) {
+		var upload model.Upload
+		if err := uploads.Decode(&upload); err != nil {
+			return nil, err
+		}
+		referenced[upload.Key] = true
+	}
+	return referenced, uploads.Err()
+}
+
+// ⏱️ Collect orphaned images now and on every tick
+func StartImageCollector(interval time.Duration) {
+	go func() {
+		for {
+			if report, err := CollectOrphanedImages(false); err != nil {
+				log.Printf("⚠️ Image collection failed: %v", err)
+			} else if report.Deleted > 0 {
+				log.Printf("🧹 Deleted %d orphaned images", report.Deleted)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
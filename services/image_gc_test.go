@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func withImageGCGrace(t *testing.T, grace time.Duration) {
+	saved := config.ImageGCGrace
+	config.ImageGCGrace = grace
+	t.Cleanup(func() { config.ImageGCGrace = saved })
+}
+
+func TestBaseImageKey(t *testing.T) {
+	original := imageFolder + "/0123456789abcdef0123456789abcdef"
+	tests := map[string]string{
+		original:                   original,
+		original + "_thumb":        original,
+		original + "_medium":       original,
+		original + "_thumbnail":    original + "_thumbnail",
+		original + "_thumb_x":      original + "_thumb_x",
+		imageFolder + "/a_thumb/b": imageFolder + "/a_thumb/b",
+		"":                         "",
+	}
+
+	for key, want := range tests {
+		if got := baseImageKey(key); got != want {
+			t.Errorf("baseImageKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestImageKeyFromURL(t *testing.T) {
+	key := imageFolder + "/0123456789abcdef"
+	tests := []struct {
+		url  string
+		want string
+		ok   bool
+	}{
+		{"https://res.cloudinary.com/demo/image/upload/v1712345678/" + key + ".jpg", key, true},
+		{"https://res.cloudinary.com/demo/image/upload/v1712345678/" + key + "_thumb.webp", key + "_thumb", true},
+		{"/uploads/" + key + "_medium.png?v=2", key + "_medium", true},
+		{"/uploads/" + key + ".jpg#top", key, true},
+		{"https://example.com/photos/rice.jpg", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := imageKeyFromURL(tt.url)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("imageKeyFromURL(%q) = %q, %v, want %q, %v", tt.url, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestClassifyStoredImages(t *testing.T) {
+	now := time.Now()
+	cutoff := now.Add(-24 * time.Hour)
+	old, recent := now.Add(-48*time.Hour), now.Add(-time.Hour)
+	live := imageFolder + "/live"
+	upload := uploadFolder + "/" + primitive.NewObjectID().Hex()
+
+	objects := []StoredObject{
+		{Key: live, CreatedAt: old},
+		{Key: live + "_thumb", CreatedAt: old},
+		{Key: live + "_medium", CreatedAt: old},
+		{Key: upload, CreatedAt: old},
+		{Key: imageFolder + "/fresh", CreatedAt: recent},
+		{Key: imageFolder + "/fresh_thumb", CreatedAt: recent},
+		{Key: imageFolder + "/gone", CreatedAt: old},
+		{Key: imageFolder + "/gone_medium", CreatedAt: old},
+		{Key: uploadFolder + "/" + primitive.NewObjectID().Hex(), CreatedAt: cutoff},
+	}
+	referenced := map[string]bool{live: true, upload: true}
+
+	report := &ImageGCReport{Orphaned: []OrphanedImage{}}
+	classifyStoredImages(report, objects, referenced, cutoff)
+	if report.Referenced != 4 || report.TooRecent != 2 {
+		t.Errorf("referenced %d, too recent %d, want 4 and 2", report.Referenced, report.TooRecent)
+	}
+	want := []string{imageFolder + "/gone", imageFolder + "/gone_medium", objects[8].Key}
+	if len(report.Orphaned) != len(want) {
+		t.Fatalf("orphaned = %+v, want %v", report.Orphaned, want)
+	}
+	for i, orphan := range report.Orphaned {
+		if orphan.Key != want[i] {
+			t.Errorf("orphan %d = %s, want %s", i, orphan.Key, want[i])
+		}
+	}
+}
+
+// TestCollectOrphanedImages needs a MongoDB server in TEST_MONGO_URI
+func TestCollectOrphanedImages(t *testing.T) {
+	testDatabase(t)
+	withImageGCGrace(t, 24*time.Hour)
+	store := newMemImageStore(t)
+	ctx := context.Background()
+
+	put := func(key string, age time.Duration) {
+		store.files[key] = []byte("x")
+		store.created[key] = time.Now().Add(-age)
+	}
+	url := func(key string) string { return "https://img.example/" + key + ".jpg" }
+
+	// A live product image and its variants, a legacy image_url, an image
+	// of a soft-deleted product and a pending upload are all kept
+	live, legacy, deleted := imageFolder+"/live", imageFolder+"/legacy", imageFolder+"/deleted"
+	pending := model.Upload{ID: primitive.NewObjectID(), Status: model.UploadPending, ExpiresAt: time.Now().Add(time.Hour)}
+	pending.Key = uploadFolder + "/" + pending.ID.Hex()
+	expired := model.Upload{ID: primitive.NewObjectID(), Status: model.UploadPending, ExpiresAt: time.Now().Add(-time.Hour)}
+	expired.Key = uploadFolder + "/" + expired.ID.Hex()
+	for _, key := range []string{live, live + "_thumb", live + "_medium", legacy, deleted, pending.Key, expired.Key} {
+		put(key, 72*time.Hour)
+	}
+	put(imageFolder+"/fresh", time.Hour)
+	put(imageFolder+"/gone", 72*time.Hour)
+	put(imageFolder+"/gone_thumb", 72*time.Hour)
+
+	deletedAt := time.Now()
+	products := []interface{}{
+		model.Product{ID: primitive.NewObjectID(), Images: []model.ProductImage{{URL: url(live), Thumbnail: url(live + "_thumb"), Medium: url(live + "_medium")}}},
+		model.Product{ID: primitive.NewObjectID(), ImageURL: url(legacy)},
+		model.Product{ID: primitive.NewObjectID(), ImageURL: url(deleted), DeletedAt: &deletedAt},
+	}
+	if _, err := productCollection.InsertMany(ctx, products); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uploadCollection.InsertMany(ctx, []interface{}{pending, expired}); err != nil {
+		t.Fatal(err)
+	}
+
+	orphans := []string{expired.Key, imageFolder + "/gone", imageFolder + "/gone_thumb"}
+	before := len(store.files)
+
+	report, err := CollectOrphanedImages(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Scanned != before || report.Referenced != 6 || report.TooRecent != 1 || len(report.Orphaned) != len(orphans) || report.Deleted != 0 {
+		t.Errorf("dry run report = %+v", report)
+	}
+	if len(store.files) != before {
+		t.Errorf("a dry run deleted %d files", before-len(store.files))
+	}
+
+	report, err = CollectOrphanedImages(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Deleted != len(orphans) {
+		t.Errorf("deleted %d, want %d", report.Deleted, len(orphans))
+	}
+	for _, key := range orphans {
+		if _, ok := store.files[key]; ok {
+			t.Errorf("orphan %s was kept", key)
+		}
+	}
+	if len(store.files) != before-len(orphans) {
+		t.Errorf("files left = %v", store.keys())
+	}
+}
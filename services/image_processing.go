@@ -0,0 +1,274 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"CROWD_MARKET/config"
+)
+
+var (
+	ErrImageTooLarge    = errors.New("image is too large")
+	ErrUnsupportedImage = errors.New("unsupported image type, use JPEG, PNG or GIF")
+)
+
+const (
+	// Decoded size cap, so a small file cannot expand into a huge bitmap
+	maxImagePixels = 40_000_000
+	jpegQuality    = 85
+)
+
+// Locally generated variants, by key suffix and longest side in pixels
+var imageVariants = []struct {
+	suffix  string
+	maxSide int
+}{
+	{"_thumb", 200},
+	{"_medium", 800},
+}
+
+// imageFormats maps sniffed MIME types to the format images are re-encoded
+// in. GIFs keep their first frame as a PNG.
+var imageFormats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/gif":  "png",
+}
+
+// encodedImage is one stored rendition
+type encodedImage struct {
+	suffix string
+	ext    string
+	data   []byte
+}
+
+// ProcessedImage is a validated upload, re-encoded without metadata, with
+// its variants. Hash names the files so identical uploads share them.
+type ProcessedImage struct {
+	Hash       string
+	Width      int
+	Height     int
+	renditions []encodedImage
+}
+
+// 🧪 ProcessImage checks that r holds a real, reasonably sized image and
+// re-encodes it. Decoding and re-encoding drops EXIF, GPS and any other
+// metadata; the EXIF orientation is applied first so photos stay upright.
+func ProcessImage(r io.Reader) (*ProcessedImage, error) {
+	data, err := io.ReadAll(io.LimitReader(r, config.MaxImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > config.MaxImageBytes {
+		return nil, fmt.Errorf("%w: the limit is %d MB", ErrImageTooLarge, config.MaxImageBytes>>20)
+	}
+
+	mimeType := http.DetectContentType(data)
+	format, ok := imageFormats[mimeType]
+	if !ok {
+		return nil, ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	var img image.Image
+	switch mimeType {
+	case "image/jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+		if err == nil {
+			img = applyOrientation(img, jpegOrientation(data))
+		}
+	case "image/png":
+		img, err = png.Decode(bytes.NewReader(data))
+	case "image/gif":
+		img, err = gif.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	original, err := encodeImage(img, format)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(original)
+	bounds := img.Bounds()
+	processed := &ProcessedImage{
+		Hash:       hex.EncodeToString(sum[:16]),
+		Width:      bounds.Dx(),
+		Height:     bounds.Dy(),
+		renditions: []encodedImage{{ext: "." + formatExt(format), data: original}},
+	}
+
+	for _, variant := range imageVariants {
+		data, err := encodeImage(resizeToFit(img, variant.maxSide), format)
+		if err != nil {
+			return nil, err
+		}
+		processed.renditions = append(processed.renditions, encodedImage{suffix: variant.suffix, ext: "." + formatExt(format), data: data})
+	}
+	return processed, nil
+}
+
+func formatExt(format string) string {
+	if format == "jpeg" {
+		return "jpg"
+	}
+	return format
+}
+
+func encodeImage(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	return buf.Bytes(), err
+}
+
+// toRGBA copies img into a premultiplied RGBA bitmap at the origin
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// resizeToFit scales img down so its longest side is at most maxSide,
+// averaging the source pixels under each target pixel. Smaller images are
+// returned unchanged.
+func resizeToFit(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+	dw, dh := maxSide, h*maxSide/w
+	if h > w {
+		dw, dh = w*maxSide/h, maxSide
+	}
+	dw, dh = max(dw, 1), max(dh, 1)
+
+	src := toRGBA(img)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+			var r, g, bl, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r, g, bl, a = r+int(p[0]), g+int(p[1]), bl+int(p[2]), a+int(p[3])
+					n++
+				}
+			}
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0], d[1], d[2], d[3] = uint8(r/n), uint8(g/n), uint8(bl/n), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// applyOrientation turns a decoded photo upright according to its EXIF
+// orientation (1-8)
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	src := toRGBA(img)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], src.Pix[y*src.Stride+x*4:y*src.Stride+x*4+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag from a JPEG's APP1
+// segment, or 1 when there is none
+func jpegOrientation(data []byte) int {
+	const orientationTag = 0x0112
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			break // image data starts; metadata comes before it
+		}
+		segment := data[i+4 : i+2+length]
+		i += 2 + length
+		if marker != 0xE1 || len(segment) < 14 || string(segment[:6]) != "Exif\x00\x00" {
+			continue
+		}
+
+		tiff := segment[6:]
+		var order binary.ByteOrder
+		switch string(tiff[:2]) {
+		case "II":
+			order = binary.LittleEndian
+		case "MM":
+			order = binary.BigEndian
+		default:
+			return 1
+		}
+		ifd := int(order.Uint32(tiff[4:]))
+		if ifd+2 > len(tiff) {
+			return 1
+		}
+		entries := int(order.Uint16(tiff[ifd:]))
+		for e := 0; e < entries; e++ {
+			entry := ifd + 2 + e*12
+			if entry+12 > len(tiff) {
+				return 1
+			}
+			if order.Uint16(tiff[entry:]) == orientationTag {
+				return int(order.Uint16(tiff[entry+8:]))
+			}
+		}
+		return 1
+	}
+	return 1
+}
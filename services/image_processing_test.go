@@ -0,0 +1,265 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"CROWD_MARKET/config"
+)
+
+// withExifOrientation splices an EXIF APP1 segment carrying orientation
+// into a JPEG, right after its SOI marker
+func withExifOrientation(jpg []byte, orientation uint16, order binary.ByteOrder) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], 0x0112)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, segment...)
+	return append(out, jpg[2:]...)
+}
+
+// testImage is w×h, with a red square in the top-left corner (half the
+// shorter side, so it survives JPEG compression) and white elsewhere
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	red := min(w, h) / 2
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < red && y < red {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+	return img
+}
+
+func encodeTestJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeTestPNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader is a PNG that declares w×h pixels but holds no image data
+func pngHeader(w, h uint32) []byte {
+	ihdr := make([]byte, 4+13)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], w)
+	binary.BigEndian.PutUint32(ihdr[8:], h)
+	ihdr[12], ihdr[13] = 8, 2 // 8-bit RGB
+
+	out := []byte("\x89PNG\r\n\x1a\n")
+	out = binary.BigEndian.AppendUint32(out, 13)
+	out = append(out, ihdr...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(ihdr))
+}
+
+func withMaxImageBytes(t *testing.T, limit int64) {
+	saved := config.MaxImageBytes
+	config.MaxImageBytes = limit
+	t.Cleanup(func() { config.MaxImageBytes = saved })
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	jpg := encodeTestJPEG(t, testImage(4, 2))
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no exif", jpg, 1},
+		{"little endian", withExifOrientation(jpg, 6, binary.LittleEndian), 6},
+		{"big endian", withExifOrientation(jpg, 8, binary.BigEndian), 8},
+		{"upright", withExifOrientation(jpg, 1, binary.BigEndian), 1},
+		{"truncated", withExifOrientation(jpg, 6, binary.BigEndian)[:20], 1},
+		{"not a jpeg", []byte("hello"), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("jpegOrientation = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessImageAppliesOrientation(t *testing.T) {
+	withMaxImageBytes(t, 1<<20)
+	jpg := encodeTestJPEG(t, testImage(40, 20))
+
+	tests := []struct {
+		orientation uint16
+		w, h        int
+		// Where the source's red top-left corner ends up
+		redX, redY int
+	}{
+		{1, 40, 20, 1, 1},
+		{3, 40, 20, 38, 18},
+		{6, 20, 40, 18, 1},
+		{8, 20, 40, 1, 38},
+	}
+
+	for _, tt := range tests {
+		processed, err := ProcessImage(bytes.NewReader(withExifOrientation(jpg, tt.orientation, binary.BigEndian)))
+		if err != nil {
+			t.Fatalf("orientation %d: %v", tt.orientation, err)
+		}
+		if processed.Width != tt.w || processed.Height != tt.h {
+			t.Errorf("orientation %d: %dx%d, want %dx%d", tt.orientation, processed.Width, processed.Height, tt.w, tt.h)
+		}
+		original := processed.renditions[0].data
+		if bytes.Contains(original, []byte("Exif")) || jpegOrientation(original) != 1 {
+			t.Errorf("orientation %d: EXIF survived re-encoding", tt.orientation)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(original))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r, g, _, _ := img.At(tt.redX, tt.redY).RGBA(); r < 0xC000 || g > 0x4000 {
+			t.Errorf("orientation %d: pixel (%d,%d) is not red", tt.orientation, tt.redX, tt.redY)
+		}
+	}
+}
+
+func TestProcessImageVariants(t *testing.T) {
+	withMaxImageBytes(t, 1<<20)
+	tests := []struct {
+		name  string
+		w, h  int
+		sizes [][2]int // original, thumb, medium
+	}{
+		{"landscape", 1000, 500, [][2]int{{1000, 500}, {200, 100}, {800, 400}}},
+		{"portrait", 300, 900, [][2]int{{300, 900}, {66, 200}, {266, 800}}},
+		{"smaller than every variant", 120, 80, [][2]int{{120, 80}, {120, 80}, {120, 80}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processed, err := ProcessImage(bytes.NewReader(encodeTestPNG(t, testImage(tt.w, tt.h))))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(processed.renditions) != len(tt.sizes) {
+				t.Fatalf("%d renditions, want %d", len(processed.renditions), len(tt.sizes))
+			}
+			for i, rendition := range processed.renditions {
+				if rendition.ext != ".png" {
+					t.Errorf("rendition %q ext = %q, want .png", rendition.suffix, rendition.ext)
+				}
+				cfg, err := png.DecodeConfig(bytes.NewReader(rendition.data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if cfg.Width != tt.sizes[i][0] || cfg.Height != tt.sizes[i][1] {
+					t.Errorf("rendition %q = %dx%d, want %dx%d", rendition.suffix, cfg.Width, cfg.Height, tt.sizes[i][0], tt.sizes[i][1])
+				}
+			}
+		})
+	}
+}
+
+func TestProcessImageRejects(t *testing.T) {
+	withMaxImageBytes(t, 4096)
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"text", []byte("definitely not an image"), ErrUnsupportedImage},
+		{"html", []byte("<html><body>hi</body></html>"), ErrUnsupportedImage},
+		{"png signature with garbage", append([]byte("\x89PNG\r\n\x1a\n"), "garbage"...), ErrUnsupportedImage},
+		{"png header without pixels", pngHeader(10, 10), ErrUnsupportedImage},
+		{"too many pixels", pngHeader(8000, 8000), ErrImageTooLarge},
+		{"too many bytes", bytes.Repeat([]byte{0}, 4097), ErrImageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ProcessImage(bytes.NewReader(tt.data)); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessImageHashIsStable(t *testing.T) {
+	withMaxImageBytes(t, 1<<20)
+	data := encodeTestPNG(t, testImage(30, 30))
+	a, err := ProcessImage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ProcessImage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Hash != b.Hash || len(a.Hash) != 32 {
+		t.Errorf("hashes %q and %q differ or are not 32 hex digits", a.Hash, b.Hash)
+	}
+}
+
+func TestResizeToFit(t *testing.T) {
+	tests := []struct {
+		w, h, maxSide int
+		wantW, wantH  int
+	}{
+		{100, 50, 200, 100, 50},
+		{200, 200, 200, 200, 200},
+		{400, 100, 200, 200, 50},
+		{100, 400, 200, 50, 200},
+		{1000, 1, 10, 10, 1},
+	}
+
+	for _, tt := range tests {
+		b := resizeToFit(testImage(tt.w, tt.h), tt.maxSide).Bounds()
+		if b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+			t.Errorf("resizeToFit(%dx%d, %d) = %dx%d, want %dx%d", tt.w, tt.h, tt.maxSide, b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+
+	// Averaging keeps uniform areas uniform
+	small := resizeToFit(testImage(64, 64), 8)
+	if r, g, b, _ := small.At(7, 7).RGBA(); r != 0xFFFF || g != 0xFFFF || b != 0xFFFF {
+		t.Errorf("bottom-right pixel = %x,%x,%x, want white", r, g, b)
+	}
+	if r, g, b, _ := small.At(0, 0).RGBA(); r != 0xFFFF || g != 0 || b != 0 {
+		t.Errorf("top-left pixel = %x,%x,%x, want red", r, g, b)
+	}
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"CROWD_MARKET/config"
+
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+const (
+	// imageFolder prefixes every stored image key
+	imageFolder = "crowd_market/products"
+	// uploadFolder holds raw direct uploads until a product claims them
+	uploadFolder = "crowd_market/incoming"
+)
+
+// ImageStore keeps processed image files. Keys are slash-separated paths
+// without an extension, e.g. "crowd_market/products/<hash>_thumb".
+type ImageStore interface {
+	// Put stores data under key and returns its public URL
+	Put(ctx context.Context, key, ext string, data []byte) (string, error)
+	// Delete removes key; a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// Exists reports whether a file is stored under key
+	Exists(ctx context.Context, key string) (bool, error)
+	// SignUpload returns a target a client can upload a file for key to
+	// directly until expires
+	SignUpload(key string, expires time.Time) (UploadTarget, error)
+	// Open reads a stored file back, or fails with ErrUploadMissing
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// List returns every stored file whose key starts with prefix + "/"
+	List(ctx context.Context, prefix string) ([]StoredObject, error)
+}
+
+// StoredObject is one file in the image store
+type StoredObject struct {
+	Key       string
+	CreatedAt time.Time
+}
+
+var imageStore ImageStore
+
+// 🖼️ Pick the image backend named by IMAGE_STORE
+func InitImageStore() {
+	switch config.ImageStore {
+	case "local":
+		// Raw uploads still carry their EXIF and GPS data, so they must not
+		// land in the publicly served directory
+		if within(config.UploadDir, config.ImageDir) {
+			log.Fatalf("❌ UPLOAD_DIR %q must not be inside IMAGE_DIR %q", config.UploadDir, config.ImageDir)
+		}
+		imageStore = &localImageStore{dir: config.ImageDir, uploadDir: config.UploadDir, baseURL: config.ImageBaseURL}
+	case "cloudinary":
+		imageStore = &cloudinaryImageStore{}
+	default:
+		// There is no S3 backend; S3-compatible storage is not supported
+		log.Printf("⚠️ Unknown IMAGE_STORE %q, using cloudinary", config.ImageStore)
+		imageStore = &cloudinaryImageStore{}
+	}
+}
+
+// within reports whether dir is parent or a directory below it
+func within(dir, parent string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absParent, err := filepath.Abs(parent)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absParent, absDir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// imageKeyFromURL recovers the store key from an image URL of either
+// backend: the path from the image folder on, without the extension
+func imageKeyFromURL(url string) (string, bool) {
+	i := strings.Index(url, imageFolder+"/")
+	if i < 0 {
+		return "", false
+	}
+	key := url[i:]
+	if q := strings.IndexAny(key, "?#"); q >= 0 {
+		key = key[:q]
+	}
+	return strings.TrimSuffix(key, path.Ext(key)), true
+}
+
+// cloudinaryImageStore stores images as Cloudinary assets whose public ID is
+// the key
+type cloudinaryImageStore struct{}
+
+func (s *cloudinaryImageStore) Put(ctx context.Context, key, ext string, data []byte) (string, error) {
+	result, err := config.Cloud.Upload.Upload(ctx, bytes.NewReader(data), uploader.UploadParams{
+		PublicID:       key,
+		Format:         strings.TrimPrefix(ext, "."),
+		UniqueFilename: api.Bool(false),
+		Overwrite:      api.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+	if result.Error.Message != "" {
+		return "", errors.New(result.Error.Message)
+	}
+	return result.SecureURL, nil
+}
+
+func (s *cloudinaryImageStore) Delete(ctx context.Context, key string) error {
+	result, err := config.Cloud.Upload.Destroy(ctx, uploader.DestroyParams{PublicID: key})
+	if err != nil {
+		return err
+	}
+	if result.Error.Message != "" {
+		return errors.New(result.Error.Message)
+	}
+	return nil
+}
+
+func (s *cloudinaryImageStore) Exists(ctx context.Context, key string) (bool, error) {
+	asset, err := config.Cloud.Admin.Asset(ctx, admin.AssetParams{PublicID: key})
+	if err != nil {
+		return false, err
+	}
+	if asset.Error.Message != "" {
+		if strings.Contains(strings.ToLower(asset.Error.Message), "not found") {
+			return false, nil
+		}
+		return false, errors.New(asset.Error.Message)
+	}
+	return true, nil
+}
+
+// SignUpload signs a Cloudinary upload pinned to key. Cloudinary honours the
+// signature for an hour; the upload slot's own expiry is checked on claim.
+func (s *cloudinaryImageStore) SignUpload(key string, expires time.Time) (UploadTarget, error) {
+	cloud := config.Cloud.Config.Cloud
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature, err := api.SignParameters(url.Values{"public_id": {key}, "timestamp": {timestamp}}, cloud.APISecret)
+	if err != nil {
+		return UploadTarget{}, err
+	}
+	return UploadTarget{
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/image/upload", cloud.CloudName),
+		Fields: map[string]string{
+			"public_id": key,
+			"timestamp": timestamp,
+			"api_key":   cloud.APIKey,
+			"signature": signature,
+		},
+	}, nil
+}
+
+func (s *cloudinaryImageStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	asset, err := config.Cloud.Admin.Asset(ctx, admin.AssetParams{PublicID: key})
+	if err != nil {
+		return nil, err
+	}
+	if asset.Error.Message != "" {
+		if strings.Contains(strings.ToLower(asset.Error.Message), "not found") {
+			return nil, ErrUploadMissing
+		}
+		return nil, errors.New(asset.Error.Message)
+	}
+	if int64(asset.Bytes) > config.MaxImageBytes {
+		return nil, fmt.Errorf("%w: the limit is %d MB", ErrImageTooLarge, config.MaxImageBytes>>20)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.SecureURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetching %s: %s", key, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (s *cloudinaryImageStore) List(ctx context.Context, prefix string) ([]StoredObject, error) {
+	var objects []StoredObject
+	params := admin.AssetsParams{AssetType: api.Image, DeliveryType: string(api.Upload), Prefix: prefix + "/", MaxResults: 500}
+	for {
+		result, err := config.Cloud.Admin.Assets(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		if result.Error.Message != "" {
+			return nil, errors.New(result.Error.Message)
+		}
+		for _, asset := range result.Assets {
+			objects = append(objects, StoredObject{Key: asset.PublicID, CreatedAt: asset.CreatedAt})
+		}
+		if result.NextCursor == "" {
+			return objects, nil
+		}
+		params.NextCursor = result.NextCursor
+	}
+}
+
+// localImageStore writes images below dir, which is served under baseURL.
+// Raw direct uploads go below uploadDir, which is not served.
+type localImageStore struct {
+	dir       string
+	uploadDir string
+	baseURL   string
+}
+
+// root is the directory holding keys below prefix
+func (s *localImageStore) root(prefix string) (string, bool) {
+	switch {
+	case prefix == imageFolder || strings.HasPrefix(prefix, imageFolder+"/"):
+		return s.dir, true
+	case prefix == uploadFolder || strings.HasPrefix(prefix, uploadFolder+"/"):
+		return s.uploadDir, true
+	}
+	return "", false
+}
+
+func (s *localImageStore) path(key, ext string) (string, error) {
+	clean := path.Clean("/" + key)[1:]
+	if clean != key || !(strings.HasPrefix(clean, imageFolder+"/") || strings.HasPrefix(clean, uploadFolder+"/")) {
+		return "", fmt.Errorf("invalid image key %q", key)
+	}
+	root, _ := s.root(clean)
+	return filepath.Join(root, filepath.FromSlash(clean)+ext), nil
+}
+
+func (s *localImageStore) Put(ctx context.Context, key, ext string, data []byte) (string, error) {
+	target, err := s.path(key, ext)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+	// Write then rename so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", err
+	}
+	return s.baseURL + "/" + key + ext, nil
+}
+
+func (s *localImageStore) Delete(ctx context.Context, key string) error {
+	pattern, err := s.path(key, ".*")
+	if err != nil {
+		return err
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		if err := os.Remove(match); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *localImageStore) Exists(ctx context.Context, key string) (bool, error) {
+	pattern, err := s.path(key, ".*")
+	if err != nil {
+		return false, err
+	}
+	matches, err := filepath.Glob(pattern)
+	return len(matches) > 0, err
+}
+
+// SignUpload points the client at the API's own PUT /uploads/:id route,
+// authorised by an HMAC over the key and expiry instead of a login
+func (s *localImageStore) SignUpload(key string, expires time.Time) (UploadTarget, error) {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	query := url.Values{"expires": {exp}, "signature": {signLocalUpload(key, exp)}}
+	return UploadTarget{
+		Method: http.MethodPut,
+		URL:    "/uploads/" + path.Base(key) + "?" + query.Encode(),
+	}, nil
+}
+
+func (s *localImageStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	pattern, err := s.path(key, ".*")
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, ErrUploadMissing
+	}
+	return os.Open(matches[0])
+}
+
+func (s *localImageStore) List(ctx context.Context, prefix string) ([]StoredObject, error) {
+	var objects []StoredObject
+	base, ok := s.root(prefix)
+	if !ok {
+		return nil, fmt.Errorf("invalid image prefix %q", prefix)
+	}
+	err := filepath.WalkDir(filepath.Join(base, filepath.FromSlash(prefix)), func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		// Skip directories and half-written temporary files
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(base, name)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		objects = append(objects, StoredObject{Key: strings.TrimSuffix(key, path.Ext(key)), CreatedAt: info.ModTime()})
+		return ctx.Err()
+	})
+	return objects, err
+}
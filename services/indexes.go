@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 🗂️ Indexes backing the listing filters and sorts. Equality fields come
+// first, then the sort key, then _id for the cursor tie-breaker.
+func productIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "price.currency", Value: 1}, {Key: "price.amount", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "confidence", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "unit_price.per", Value: 1}, {Key: "price.currency", Value: 1}, {Key: "unit_price.amount", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "category", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "category", Value: 1}, {Key: "price.currency", Value: 1}, {Key: "price.amount", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "area", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "area", Value: 1}, {Key: "category", Value: 1}, {Key: "price.currency", Value: 1}, {Key: "price.amount", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "location_path", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "location", Value: "2dsphere"}}},
+		{
+			Keys: bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}, {Key: "category", Value: "text"}},
+			Options: options.Index().SetName("product_text").SetWeights(bson.M{
+				"name":        searchWeights["name"],
+				"category":    searchWeights["category"],
+				"description": searchWeights["description"],
+			}),
+		},
+	}
+}
+
+// ✅ Create indexes at startup. Failures are logged rather than fatal so the
+// API still serves (slower) queries if index creation is not permitted.
+func ensureIndexes(coll *mongo.Collection, models []mongo.IndexModel) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := coll.Indexes().CreateMany(ctx, models, options.CreateIndexes()); err != nil {
+		log.Printf("⚠️ Failed to create indexes on %s: %v", coll.Name(), err)
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ItemMigrationGroup struct {
+	ItemName       string   `json:"item_name"`
+	NormalizedName string   `json:"normalized_name"`
+	Category       string   `json:"category"`
+	ExistingItem   bool     `json:"existing_item"`
+	ProductIDs     []string `json:"product_ids"`
+}
+
+type ItemMigrationReport struct {
+	DryRun          bool                 `json:"dry_run"`
+	ProductsScanned int                  `json:"products_scanned"`
+	ItemsCreated    int                  `json:"items_created"`
+	ItemsMatched    int                  `json:"items_matched"`
+	ReportsCreated  int                  `json:"reports_created"`
+	Groups          []ItemMigrationGroup `json:"groups"`
+}
+
+// 🧭 MigrateProductsToItems groups products that have no catalog item by
+// normalized name, links each group to one item and writes a price report
+// per product. It is safe to re-run: linked products are skipped.
+func MigrateProductsToItems(dryRun bool) (*ItemMigrationReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	cursor, err := productCollection.Find(ctx, bson.M{"item_id": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	groups := map[string][]model.Product{}
+	report := &ItemMigrationReport{DryRun: dryRun}
+	for cursor.Next(ctx) {
+		var p model.Product
+		if err := cursor.Decode(&p); err != nil {
+			return nil, err
+		}
+		report.ProductsScanned++
+		if normalized := NormalizeItemName(p.Name); normalized != "" {
+			groups[normalized] = append(groups[normalized], p)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(groups))
+	for normalized := range groups {
+		names = append(names, normalized)
+	}
+	sort.Strings(names)
+
+	for _, normalized := range names {
+		products := groups[normalized]
+		group := ItemMigrationGroup{
+			ItemName:       products[0].Name,
+			NormalizedName: normalized,
+			Category:       dominantCategory(products),
+		}
+		for _, p := range products {
+			group.ProductIDs = append(group.ProductIDs, p.ID.Hex())
+		}
+
+		existing, err := findItemByNormalizedName(ctx, normalized)
+		if err != nil {
+			return nil, err
+		}
+		group.ExistingItem = existing != nil
+		if existing != nil {
+			report.ItemsMatched++
+		} else {
+			report.ItemsCreated++
+		}
+		report.Groups = append(report.Groups, group)
+
+		if dryRun {
+			report.ReportsCreated += len(products)
+			continue
+		}
+
+		item := existing
+		if item == nil {
+			if item, err = FindOrCreateItem(ctx, group.ItemName, group.Category); err != nil {
+				return nil, err
+			}
+		}
+
+		created, err := linkProductsToItem(ctx, products, item.ID)
+		if err != nil {
+			return nil, err
+		}
+		report.ReportsCreated += created
+	}
+
+	return report, nil
+}
+
+// linkProductsToItem sets item_id on the products and inserts a price report
+// for each product that does not already have one.
+func linkProductsToItem(ctx context.Context, products []model.Product, itemID primitive.ObjectID) (int, error) {
+	ids := make([]primitive.ObjectID, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+
+	reported := map[primitive.ObjectID]bool{}
+	cursor, err := priceReportCollection.Find(ctx, bson.M{"product_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+	var existing []model.PriceReport
+	if err := cursor.All(ctx, &existing); err != nil {
+		return 0, err
+	}
+	for _, r := range existing {
+		reported[r.ProductID] = true
+	}
+
+	var docs []interface{}
+	for _, p := range products {
+		if !reported[p.ID] {
+			docs = append(docs, priceReportFor(p, itemID))
+		}
+	}
+	if len(docs) > 0 {
+		if _, err := priceReportCollection.InsertMany(ctx, docs); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = productCollection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{"$set": bson.M{"item_id": itemID}})
+	return len(docs), err
+}
+
+// dominantCategory picks the most common non-empty category in a group
+func dominantCategory(products []model.Product) string {
+	counts := map[string]int{}
+	best := ""
+	for _, p := range products {
+		if p.Category == "" {
+			continue
+		}
+		counts[p.Category]++
+		if counts[p.Category] > counts[best] || (counts[p.Category] == counts[best] && p.Category < best) {
+			best = p.Category
+		}
+	}
+	return best
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestMigrateProductsToItemsGroupsVariants needs a MongoDB server in
+// TEST_MONGO_URI
+func TestMigrateProductsToItemsGroupsVariants(t *testing.T) {
+	testDatabase(t)
+	ctx := context.Background()
+
+	existing := model.Item{ID: primitive.NewObjectID(), Name: "Beans", NormalizedName: "beans", Aliases: []string{}}
+	if _, err := itemCollection.InsertOne(ctx, existing); err != nil {
+		t.Fatal(err)
+	}
+	products := []model.Product{
+		{ID: primitive.NewObjectID(), Name: "Rice 50 KG", Category: "grains"},
+		{ID: primitive.NewObjectID(), Name: "rice, 50kgs", Category: "grains"},
+		{ID: primitive.NewObjectID(), Name: "Rice 5kg"},
+		{ID: primitive.NewObjectID(), Name: "BEANS."},
+		{ID: primitive.NewObjectID(), Name: "Beans", ItemID: existing.ID},
+		{ID: primitive.NewObjectID(), Name: "???"},
+	}
+	for _, p := range products {
+		if _, err := productCollection.InsertOne(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := MigrateProductsToItems(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ProductsScanned != 5 || report.ItemsCreated != 2 || report.ItemsMatched != 1 || report.ReportsCreated != 4 {
+		t.Errorf("report = %+v", report)
+	}
+	groups := map[string]ItemMigrationGroup{}
+	for _, group := range report.Groups {
+		groups[group.NormalizedName] = group
+	}
+	if g := groups["rice 50kg"]; len(g.ProductIDs) != 2 || g.Category != "grains" || g.ExistingItem {
+		t.Errorf("rice 50kg group = %+v", g)
+	}
+	if g := groups["beans"]; len(g.ProductIDs) != 1 || !g.ExistingItem {
+		t.Errorf("beans group = %+v", g)
+	}
+	if count, _ := priceReportCollection.CountDocuments(ctx, bson.M{}); count != 0 {
+		t.Errorf("a dry run wrote %d price reports", count)
+	}
+
+	// A real run links every product, and a second run finds nothing to do
+	if _, err := MigrateProductsToItems(false); err != nil {
+		t.Fatal(err)
+	}
+	again, err := MigrateProductsToItems(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ProductsScanned != 1 || again.ReportsCreated != 0 {
+		t.Errorf("second run = %+v, want only the unnamed product left", again)
+	}
+}
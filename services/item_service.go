@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	itemCollection        *mongo.Collection
+	priceReportCollection *mongo.Collection
+)
+
+func InitItemService() {
+	itemCollection = config.DB.Collection("items")
+	priceReportCollection = config.DB.Collection("price_reports")
+
+	ensureIndexes(itemCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "normalized_name", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "aliases", Value: 1}}},
+		{Keys: bson.D{{Key: "category", Value: 1}, {Key: "name", Value: 1}}},
+	})
+	ensureIndexes(priceReportCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "item_id", Value: 1}, {Key: "area", Value: 1}, {Key: "observed_at", Value: -1}}},
+		{Keys: bson.D{{Key: "item_id", Value: 1}, {Key: "observed_at", Value: -1}}},
+		{Keys: bson.D{{Key: "product_id", Value: 1}}},
+	})
+}
+
+var quantityPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*(kg|g|l|ml|cl)s?\b`)
+
+// NormalizeItemName folds case, punctuation, spacing and plural pack units so
+// that "Rice 50 KGS." and "rice, 50kg" map to the same catalog entry. A dot is
+// kept only as a decimal point. Plural words are not folded.
+func NormalizeItemName(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(unicode.ToLower(r))
+		case r == '.' && i > 0 && i+1 < len(runes) && unicode.IsDigit(runes[i-1]) && unicode.IsDigit(runes[i+1]):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	cleaned := strings.Join(strings.Fields(b.String()), " ")
+	return quantityPattern.ReplaceAllString(cleaned, "$1$2")
+}
+
+// itemUnit picks the pack size out of a normalized name, e.g. "50kg"
+func itemUnit(normalized string) string {
+	if m := quantityPattern.FindString(normalized); m != "" {
+		return m
+	}
+	return "piece"
+}
+
+// ✅ Find the catalog item for a product name, creating it on first sight
+func FindOrCreateItem(ctx context.Context, name, category string) (*model.Item, error) {
+	normalized := NormalizeItemName(name)
+	if normalized == "" {
+		return nil, errors.New("item name is required")
+	}
+
+	existing, err := findItemByNormalizedName(ctx, normalized)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	now := time.Now()
+	item := model.Item{
+		ID:             primitive.NewObjectID(),
+		Name:           strings.TrimSpace(name),
+		NormalizedName: normalized,
+		Category:       category,
+		Unit:           itemUnit(normalized),
+		Aliases:        []string{},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	// Upsert keeps concurrent first reports of the same item from racing
+	err = itemCollection.FindOneAndUpdate(ctx,
+		bson.M{"normalized_name": normalized},
+		bson.M{"$setOnInsert": item},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&item)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// findItemByNormalizedName matches canonical names and aliases; nil if absent
+func findItemByNormalizedName(ctx context.Context, normalized string) (*model.Item, error) {
+	var item model.Item
+	err := itemCollection.FindOne(ctx, bson.M{"$or": bson.A{
+		bson.M{"normalized_name": normalized},
+		bson.M{"aliases": normalized},
+	}}).Decode(&item)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ✅ Get a catalog item by ID
+func GetItemByID(id string) (*model.Item, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := toObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var item model.Item
+	if err := itemCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&item); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("item not found")
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ✅ List catalog items, optionally by category or name prefix
+func ListItems(category, query string, limit int) ([]model.Item, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if category != "" {
+		filter["category"] = category
+	}
+	if q := NormalizeItemName(query); q != "" {
+		prefix := "^" + regexp.QuoteMeta(q)
+		filter["$or"] = bson.A{
+			bson.M{"normalized_name": bson.M{"$regex": prefix}},
+			bson.M{"aliases": bson.M{"$regex": prefix}},
+		}
+	}
+	if limit <= 0 || limit > config.MaxProductPageSize {
+		limit = config.ProductPageSize
+	}
+
+	cursor, err := itemCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items := []model.Item{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ✅ Latest price reports for an item. Reports of listings a moderator took
+// down are left out.
+func GetItemReports(itemID, area string, limit int) ([]model.PriceReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := toObjectID(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"item_id": objID, "status": bson.M{"$ne": model.ReportStatusRemoved}}
+	if area != "" {
+		filter["area"] = area
+	}
+	if limit <= 0 || limit > config.MaxProductPageSize {
+		limit = config.ProductPageSize
+	}
+
+	cursor, err := priceReportCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "observed_at", Value: -1}}).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reports := []model.PriceReport{}
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// priceReportFor builds the report that mirrors a product listing
+func priceReportFor(product model.Product, itemID primitive.ObjectID) model.PriceReport {
+	observed := time.Now()
+	if product.CreatedAt != nil {
+		observed = *product.CreatedAt
+	}
+	status := product.Status
+	if status == "" {
+		status = model.ReportStatusPublished
+	}
+	return model.PriceReport{
+		ID:           primitive.NewObjectID(),
+		ItemID:       itemID,
+		ProductID:    product.ID,
+		ReporterID:   product.UserID,
+		Area:         product.Area,
+		LocationID:   product.LocationID,
+		LocationPath: product.LocationPath,
+		MarketID:     product.MarketID,
+		Price:        product.Price,
+		Location:     product.Location,
+		ObservedAt:   observed,
+		Status:       status,
+		Weight:       neutralReputation,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// 📝 Record the price report for a freshly saved product that is already
+// linked to its item. If the insert fails the link is removed again so the
+// item migration picks the product up later.
+func recordPriceReport(ctx context.Context, product *model.Product, assessment PriceAssessment, reputation float64) {
+	report := priceReportFor(*product, product.ItemID)
+	report.OutlierScore = assessment.Score
+	report.Weight = reportWeight(reputation)
+
+	if _, err := priceReportCollection.InsertOne(ctx, report); err != nil {
+		log.Printf("⚠️ Failed to record price report for product %s: %v", product.ID.Hex(), err)
+		if _, err := productCollection.UpdateOne(ctx, bson.M{"_id": product.ID}, bson.M{"$unset": bson.M{"item_id": ""}}); err != nil {
+			log.Printf("⚠️ Failed to unlink product %s: %v", product.ID.Hex(), err)
+		}
+		product.ItemID = primitive.NilObjectID
+	}
+}
+
+// 🔄 Keep the report in step with edits to its product listing. A changed
+// price or area is scored again, which can flag or clear the listing.
+func syncPriceReport(ctx context.Context, product *model.Product) {
+	item, err := FindOrCreateItem(ctx, product.Name, product.Category)
+	if err != nil {
+		log.Printf("⚠️ Failed to resolve item for product %s: %v", product.ID.Hex(), err)
+		return
+	}
+
+	assessment := assessPrice(ctx, item.ID, product.Area, product.Price, product.ID)
+	if product.Status == model.ReportStatusPending && assessment.Status == model.ReportStatusPublished {
+		// Still waiting for a moderator; an edit does not publish it
+		assessment.Status = model.ReportStatusPending
+	}
+
+	productUpdate := bson.M{"item_id": item.ID, "status": assessment.Status}
+	if _, err := productCollection.UpdateOne(ctx, bson.M{"_id": product.ID}, bson.M{"$set": productUpdate}); err != nil {
+		log.Printf("⚠️ Failed to relink product %s: %v", product.ID.Hex(), err)
+		return
+	}
+	product.ItemID = item.ID
+	product.Status = assessment.Status
+
+	update := bson.M{"$set": bson.M{
+		"item_id":       item.ID,
+		"area":          product.Area,
+		"location_id":   product.LocationID,
+		"location_path": product.LocationPath,
+		"market_id":     product.MarketID,
+		"price":         product.Price,
+		"location":      product.Location,
+		"status":        reportStatusFor(*product),
+		"outlier_score": assessment.Score,
+	}}
+	if _, err := priceReportCollection.UpdateOne(ctx, bson.M{"product_id": product.ID}, update); err != nil {
+		log.Printf("⚠️ Failed to sync price report for product %s: %v", product.ID.Hex(), err)
+	}
+}
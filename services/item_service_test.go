@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNormalizeItemName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Rice", "rice"},
+		{"  RICE  ", "rice"},
+		{"Ofada\tRice\n", "ofada rice"},
+		{"Rice 50 KG", "rice 50kg"},
+		{"rice, 50kg", "rice 50kg"},
+		{"Rice (50kg)", "rice 50kg"},
+		{"Rice - 50 Kg.", "rice 50kg"},
+		{"Rice 50kgs", "rice 50kg"},
+		{"Groundnut oil 1.5 L", "groundnut oil 1.5l"},
+		{"Groundnut oil 1.5 litres", "groundnut oil 1.5 litres"},
+		{"Peak milk 400 g", "peak milk 400g"},
+		{"Coke 50cl x 12", "coke 50cl x 12"},
+		{"Rice.", "rice"},
+		{"St. Louis sugar", "st louis sugar"},
+		{"Palm-oil", "palm oil"},
+		{"Crème fraîche", "crème fraîche"},
+		{"!!!", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeItemName(tt.name); got != tt.want {
+			t.Errorf("NormalizeItemName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeItemNameCollisions(t *testing.T) {
+	same := [][]string{
+		{"Rice 50 KG", "rice, 50kg", "RICE (50 kgs)", "rice-50kg."},
+		{"Garri (white)", "garri white", "Garri, White!"},
+		{"Vegetable oil 1.5L", "vegetable oil 1.5 l", "Vegetable Oil - 1.5ls"},
+	}
+	for _, names := range same {
+		want := NormalizeItemName(names[0])
+		for _, name := range names[1:] {
+			if got := NormalizeItemName(name); got != want {
+				t.Errorf("%q normalizes to %q, want %q like %q", name, got, want, names[0])
+			}
+		}
+	}
+
+	// Different items must stay apart. Plural words are not folded: a
+	// stemmer would merge "glass" with "glasses" and worse.
+	different := [][2]string{
+		{"Rice 50kg", "Rice 5kg"},
+		{"Oil 1.5l", "Oil 15l"},
+		{"Tomato", "Tomatoes"},
+		{"Beans", "Bean cake"},
+		{"Palm oil", "Palm wine"},
+	}
+	for _, pair := range different {
+		if NormalizeItemName(pair[0]) == NormalizeItemName(pair[1]) {
+			t.Errorf("%q and %q normalize to the same name", pair[0], pair[1])
+		}
+	}
+}
+
+func TestItemUnit(t *testing.T) {
+	tests := map[string]string{
+		"Rice 50 KG":          "50kg",
+		"Vegetable oil 1.5 L": "1.5l",
+		"Milk 400gs":          "400g",
+		"Yam tuber":           "piece",
+	}
+	for name, want := range tests {
+		if got := itemUnit(NormalizeItemName(name)); got != want {
+			t.Errorf("itemUnit(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestDominantCategory(t *testing.T) {
+	products := func(categories ...string) []model.Product {
+		list := make([]model.Product, len(categories))
+		for i, category := range categories {
+			list[i] = model.Product{Category: category}
+		}
+		return list
+	}
+	tests := []struct {
+		products []model.Product
+		want     string
+	}{
+		{products(), ""},
+		{products("", ""), ""},
+		{products("grains", "", "tubers", "grains"), "grains"},
+		{products("tubers", "grains"), "grains"},
+	}
+	for _, tt := range tests {
+		if got := dominantCategory(tt.products); got != tt.want {
+			t.Errorf("dominantCategory = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+// TestFindOrCreateItemMatchesVariants needs a MongoDB server in
+// TEST_MONGO_URI
+func TestFindOrCreateItemMatchesVariants(t *testing.T) {
+	testDatabase(t)
+	ctx := context.Background()
+
+	first, err := FindOrCreateItem(ctx, "Rice 50 KG", "grains")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.NormalizedName != "rice 50kg" || first.Unit != "50kg" || first.Name != "Rice 50 KG" {
+		t.Errorf("item = %+v", first)
+	}
+	for _, name := range []string{"rice, 50kg", "RICE (50 kgs)."} {
+		item, err := FindOrCreateItem(ctx, name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.ID != first.ID {
+			t.Errorf("%q created item %s, want %s", name, item.ID.Hex(), first.ID.Hex())
+		}
+	}
+
+	other, err := FindOrCreateItem(ctx, "Rice 5kg", "grains")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.ID == first.ID {
+		t.Error("a 5kg bag matched the 50kg item")
+	}
+
+	// An alias matches as well as the canonical name
+	aliased := model.Item{ID: primitive.NewObjectID(), Name: "Garri", NormalizedName: "garri", Aliases: []string{"gari", "eba flakes"}}
+	if _, err := itemCollection.InsertOne(ctx, aliased); err != nil {
+		t.Fatal(err)
+	}
+	if item, err := FindOrCreateItem(ctx, "Eba Flakes!", ""); err != nil || item.ID != aliased.ID {
+		t.Errorf("alias lookup = %+v, %v, want %s", item, err, aliased.ID.Hex())
+	}
+
+	if _, err := FindOrCreateItem(ctx, " ... ", ""); err == nil {
+		t.Error("a name with no letters or digits was accepted")
+	}
+}
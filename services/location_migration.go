@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Similarity needed to link an area automatically, and the lowest score
+// still offered to a reviewer as a candidate
+const (
+	areaMatchThreshold     = 0.8
+	areaCandidateThreshold = 0.6
+	// The runner-up must trail the best match by this much, otherwise the
+	// area is ambiguous ("Yaba" in two states) and goes to review
+	areaMatchMargin   = 0.05
+	areaMaxCandidates = 3
+)
+
+type AreaCandidate struct {
+	LocationID primitive.ObjectID `json:"location_id"`
+	Path       string             `json:"path"`
+	Score      float64            `json:"score"`
+}
+
+type AreaMatch struct {
+	Area       string          `json:"area"`
+	Products   int             `json:"products"`
+	Reports    int             `json:"reports"`
+	Match      *AreaCandidate  `json:"match,omitempty"`
+	Candidates []AreaCandidate `json:"candidates,omitempty"`
+}
+
+type LocationMigrationReport struct {
+	DryRun         bool        `json:"dry_run"`
+	Matched        []AreaMatch `json:"matched"`
+	NeedsReview    []AreaMatch `json:"needs_review"`
+	ProductsLinked int         `json:"products_linked"`
+	ReportsLinked  int         `json:"reports_linked"`
+}
+
+// locationNames are the normalized spellings a free-text area may use for a
+// location: its name and aliases, alone or followed by its parents' names
+func locationNames(l model.Location, byID map[primitive.ObjectID]model.Location) []string {
+	var parents []string
+	for i := len(l.Ancestors) - 1; i >= 0; i-- {
+		if a, ok := byID[l.Ancestors[i]]; ok {
+			parents = append(parents, NormalizeAreaName(a.Name))
+		}
+	}
+
+	var names []string
+	for _, term := range l.SearchTerms {
+		name := term
+		names = append(names, name)
+		for _, parent := range parents {
+			name += " " + parent
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// similarity is 1 minus the edit distance relative to the longer string
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+// matchArea scores a free-text area against every location's names. It
+// returns the match when the best candidate is confident and unambiguous;
+// otherwise no match and the best few candidates for a reviewer.
+func matchArea(area string, locations []model.Location, names map[primitive.ObjectID][]string) (*AreaCandidate, []AreaCandidate) {
+	normalized := NormalizeAreaName(area)
+	var candidates []AreaCandidate
+	for _, l := range locations {
+		best := 0.0
+		for _, name := range names[l.ID] {
+			if score := similarity(normalized, name); score > best {
+				best = score
+			}
+		}
+		if best >= areaCandidateThreshold {
+			candidates = append(candidates, AreaCandidate{LocationID: l.ID, Path: l.Path, Score: best})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Path < candidates[j].Path
+	})
+
+	confident := len(candidates) > 0 && candidates[0].Score >= areaMatchThreshold &&
+		(len(candidates) == 1 || candidates[0].Score-candidates[1].Score >= areaMatchMargin)
+	if confident {
+		return &candidates[0], nil
+	}
+	if len(candidates) > areaMaxCandidates {
+		candidates = candidates[:areaMaxCandidates]
+	}
+	return nil, candidates
+}
+
+// unlinkedAreaCounts counts records per free-text area that have no location
+func unlinkedAreaCounts(ctx context.Context, coll *mongo.Collection) (map[string]int, error) {
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"location_id": nil, "area": bson.M{"$nin": bson.A{nil, ""}}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$area", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Area  string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Area] = row.Count
+	}
+	return counts, nil
+}
+
+// 🗺️ MigrateAreasToLocations links products and price reports that only
+// carry a free-text area to the closest canonical location. Confident,
+// unambiguous matches are applied; everything else is listed for review
+// with its best candidates. Linked records are not matched again, so it is
+// safe to re-run after adding locations or aliases.
+func MigrateAreasToLocations(dryRun bool) (*LocationMigrationReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	cursor, err := locationCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var locations []model.Location
+	if err := cursor.All(ctx, &locations); err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]model.Location, len(locations))
+	for _, l := range locations {
+		byID[l.ID] = l
+	}
+	names := make(map[primitive.ObjectID][]string, len(locations))
+	for _, l := range locations {
+		names[l.ID] = locationNames(l, byID)
+	}
+
+	productAreas, err := unlinkedAreaCounts(ctx, productCollection)
+	if err != nil {
+		return nil, err
+	}
+	reportAreas, err := unlinkedAreaCounts(ctx, priceReportCollection)
+	if err != nil {
+		return nil, err
+	}
+	areas := make([]string, 0, len(productAreas)+len(reportAreas))
+	for area := range productAreas {
+		areas = append(areas, area)
+	}
+	for area := range reportAreas {
+		if _, seen := productAreas[area]; !seen {
+			areas = append(areas, area)
+		}
+	}
+	sort.Strings(areas)
+
+	report := &LocationMigrationReport{DryRun: dryRun, Matched: []AreaMatch{}, NeedsReview: []AreaMatch{}}
+	for _, area := range areas {
+		match, candidates := matchArea(area, locations, names)
+		entry := AreaMatch{Area: area, Products: productAreas[area], Reports: reportAreas[area]}
+		if match == nil {
+			entry.Candidates = candidates
+			report.NeedsReview = append(report.NeedsReview, entry)
+			continue
+		}
+
+		entry.Match = match
+		report.Matched = append(report.Matched, entry)
+		if dryRun {
+			report.ProductsLinked += entry.Products
+			report.ReportsLinked += entry.Reports
+			continue
+		}
+
+		location := byID[match.LocationID]
+		filter := bson.M{"area": area, "location_id": nil}
+		update := bson.M{"$set": bson.M{"location_id": location.ID, "location_path": location.Lineage()}}
+		products, err := productCollection.UpdateMany(ctx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+		reports, err := priceReportCollection.UpdateMany(ctx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+		report.ProductsLinked += int(products.ModifiedCount)
+		report.ReportsLinked += int(reports.ModifiedCount)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// locationTree builds locations the way CreateLocation does, without a
+// database
+type locationTree struct {
+	all []model.Location
+}
+
+func (tree *locationTree) add(name, locationType string, parent *model.Location, aliases ...string) *model.Location {
+	l := model.Location{ID: primitive.NewObjectID(), Name: name, Type: locationType, Path: name}
+	if parent != nil {
+		l.ParentID = &parent.ID
+		l.Ancestors = append(append([]primitive.ObjectID{}, parent.Ancestors...), parent.ID)
+		l.Path = name + ", " + parent.Path
+	}
+	l.SearchTerms = []string{NormalizeAreaName(name)}
+	for _, alias := range aliases {
+		l.Aliases = append(l.Aliases, alias)
+		l.SearchTerms = append(l.SearchTerms, NormalizeAreaName(alias))
+	}
+	tree.all = append(tree.all, l)
+	return &l
+}
+
+func (tree *locationTree) names() map[primitive.ObjectID][]string {
+	byID := make(map[primitive.ObjectID]model.Location, len(tree.all))
+	for _, l := range tree.all {
+		byID[l.ID] = l
+	}
+	names := make(map[primitive.ObjectID][]string, len(tree.all))
+	for _, l := range tree.all {
+		names[l.ID] = locationNames(l, byID)
+	}
+	return names
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"yaba", "yaba", 1},
+		{"", "", 1},
+		{"yaba", "", 0},
+		{"yabba", "yaba", 0.8},
+		{"ikoyi", "ikota", 0.6},
+		{"lagos", "sogal", 0.2},
+		{"ìkòyí", "ìkòyí", 1},
+	}
+	for _, tt := range tests {
+		if got := similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := similarity(tt.b, tt.a); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("similarity(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestLocationNames(t *testing.T) {
+	var tree locationTree
+	nigeria := tree.add("Nigeria", model.LocationCountry, nil)
+	state := tree.add("Lagos State", model.LocationState, nigeria)
+	lagos := tree.add("Lagos", model.LocationCity, state)
+	yaba := tree.add("Yaba", model.LocationArea, lagos, "Yaba Tech")
+
+	want := []string{
+		"yaba", "yaba lagos", "yaba lagos lagos state", "yaba lagos lagos state nigeria",
+		"yaba tech", "yaba tech lagos", "yaba tech lagos lagos state", "yaba tech lagos lagos state nigeria",
+	}
+	got := tree.names()[yaba.ID]
+	if len(got) != len(want) {
+		t.Fatalf("names = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("name %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMatchArea(t *testing.T) {
+	var tree locationTree
+	nigeria := tree.add("Nigeria", model.LocationCountry, nil)
+	lagosState := tree.add("Lagos State", model.LocationState, nigeria)
+	lagos := tree.add("Lagos", model.LocationCity, lagosState)
+	yaba := tree.add("Yaba", model.LocationArea, lagos)
+	surulere := tree.add("Surulere", model.LocationArea, lagos, "Shitta")
+	ikota := tree.add("Ikota", model.LocationArea, lagos)
+	tree.add("Ikoyi", model.LocationArea, lagos)
+	for _, place := range [][2]string{{"Oyo State", "Ibadan"}, {"Kaduna State", "Kaduna"}, {"Niger State", "Minna"}} {
+		state := tree.add(place[0], model.LocationState, nigeria)
+		city := tree.add(place[1], model.LocationCity, state)
+		tree.add("Yaba", model.LocationArea, city)
+	}
+	names := tree.names()
+
+	tests := []struct {
+		area string
+		// want is the matched location; nil means the area goes to review
+		want           *model.Location
+		wantScore      float64
+		wantCandidates int
+	}{
+		{"Yaba, Lagos", yaba, 1, 0},
+		{"YABA  lagos, lagos-state", yaba, 1, 0},
+		{"Yabba Lagos", yaba, 1 - 1.0/11, 0},
+		{"Lagos", lagos, 1, 0},
+		{"Surulere", surulere, 1, 0},
+		{"Shitta, Lagos", surulere, 1, 0},
+		// Exactly at the threshold, well clear of Ikoyi
+		{"Ikoto", ikota, areaMatchThreshold, 0},
+		// The same distance from Ikota and Ikoyi
+		{"Ikoti", nil, 0, 2},
+		// Four Yabas in four states: only the best three are offered
+		{"Yaba", nil, 0, areaMaxCandidates},
+		// Names only extend through every parent, so skipping the city is
+		// left to a reviewer
+		{"Yaba, Lagos State", nil, 0, 3},
+		// Close enough to offer, not to link
+		{"Surlr", nil, 0, 1},
+		{"Timbuktu", nil, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.area, func(t *testing.T) {
+			match, candidates := matchArea(tt.area, tree.all, names)
+			if tt.want == nil {
+				if match != nil {
+					t.Fatalf("matched %s (%.3f), want review", match.Path, match.Score)
+				}
+				if len(candidates) != tt.wantCandidates {
+					t.Fatalf("candidates = %+v, want %d", candidates, tt.wantCandidates)
+				}
+				for i, candidate := range candidates {
+					if candidate.Score < areaCandidateThreshold {
+						t.Errorf("candidate %s scores %.3f, below the candidate threshold", candidate.Path, candidate.Score)
+					}
+					if i > 0 && candidate.Score > candidates[i-1].Score {
+						t.Errorf("candidates are not best first: %+v", candidates)
+					}
+				}
+				return
+			}
+			if match == nil {
+				t.Fatalf("no match, candidates %+v, want %s", candidates, tt.want.Path)
+			}
+			if match.LocationID != tt.want.ID || math.Abs(match.Score-tt.wantScore) > 1e-9 {
+				t.Errorf("matched %s (%.3f), want %s (%.3f)", match.Path, match.Score, tt.want.Path, tt.wantScore)
+			}
+			if candidates != nil {
+				t.Errorf("a match also returned candidates %+v", candidates)
+			}
+		})
+	}
+}
+
+func TestMatchAreaTiesAreOrderedByPath(t *testing.T) {
+	var tree locationTree
+	nigeria := tree.add("Nigeria", model.LocationCountry, nil)
+	for _, state := range []string{"Oyo State", "Kaduna State", "Lagos State"} {
+		tree.add("Yaba", model.LocationArea, tree.add(state, model.LocationState, nigeria))
+	}
+
+	_, candidates := matchArea("Yaba", tree.all, tree.names())
+	want := []string{"Yaba, Kaduna State, Nigeria", "Yaba, Lagos State, Nigeria", "Yaba, Oyo State, Nigeria"}
+	if len(candidates) != len(want) {
+		t.Fatalf("candidates = %+v", candidates)
+	}
+	for i := range want {
+		if candidates[i].Path != want[i] {
+			t.Errorf("candidate %d = %s, want %s", i, candidates[i].Path, want[i])
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var locationCollection *mongo.Collection
+
+var ErrLocationNotFound = errors.New("location not found")
+
+const (
+	defaultAutocompleteLimit = 10
+	maxAutocompleteLimit     = 50
+)
+
+func InitLocationService() {
+	locationCollection = config.DB.Collection("locations")
+	ensureIndexes(locationCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "search_terms", Value: 1}, {Key: "type", Value: 1}}},
+		{Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "name", Value: 1}}},
+		{Keys: bson.D{{Key: "ancestors", Value: 1}}},
+	})
+}
+
+// NormalizeAreaName folds case, punctuation and spacing so that "Yaba, Lagos"
+// and "yaba lagos" compare equal
+func NormalizeAreaName(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, name)
+	return strings.Join(strings.Fields(cleaned), " ")
+}
+
+type LocationInput struct {
+	Name     string
+	Type     string
+	ParentID string
+	Aliases  []string
+}
+
+// ✅ Add a location under its parent. Countries are top-level; every other
+// level needs a parent from a wider level.
+func CreateLocation(input LocationInput) (*model.Location, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	name := strings.TrimSpace(input.Name)
+	if NormalizeAreaName(name) == "" {
+		return nil, &FilterError{Err: errors.New("name is required")}
+	}
+	level, ok := model.LocationLevels[input.Type]
+	if !ok {
+		return nil, &FilterError{Err: errors.New("type must be country, state, city, area or market")}
+	}
+
+	location := model.Location{
+		ID:        primitive.NewObjectID(),
+		Name:      name,
+		Type:      input.Type,
+		Ancestors: []primitive.ObjectID{},
+		Path:      name,
+		CreatedAt: time.Now(),
+	}
+
+	if input.Type == model.LocationCountry {
+		if input.ParentID != "" {
+			return nil, &FilterError{Err: errors.New("a country cannot have a parent")}
+		}
+	} else {
+		if input.ParentID == "" {
+			return nil, &FilterError{Err: fmt.Errorf("a %s needs a parent", input.Type)}
+		}
+		parent, err := GetLocation(input.ParentID)
+		if err != nil {
+			return nil, &FilterError{Err: fmt.Errorf("parent: %w", err)}
+		}
+		if model.LocationLevels[parent.Type] >= level {
+			return nil, &FilterError{Err: fmt.Errorf("a %s cannot be placed under a %s", input.Type, parent.Type)}
+		}
+		location.ParentID = &parent.ID
+		location.Ancestors = append(append(location.Ancestors, parent.Ancestors...), parent.ID)
+		location.Path = name + ", " + parent.Path
+	}
+
+	location.SearchTerms = []string{NormalizeAreaName(name)}
+	for _, alias := range input.Aliases {
+		if alias = strings.TrimSpace(alias); alias == "" {
+			continue
+		}
+		location.Aliases = append(location.Aliases, alias)
+		location.SearchTerms = append(location.SearchTerms, NormalizeAreaName(alias))
+	}
+
+	siblings := bson.M{"parent_id": location.ParentID, "search_terms": location.SearchTerms[0]}
+	if location.ParentID == nil {
+		siblings["parent_id"] = nil
+	}
+	if exists, err := locationCollection.CountDocuments(ctx, siblings); err != nil {
+		return nil, err
+	} else if exists > 0 {
+		return nil, &FilterError{Err: fmt.Errorf("%q already exists there", name)}
+	}
+
+	if _, err := locationCollection.InsertOne(ctx, location); err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// ✅ Get a location by ID
+func GetLocation(id string) (*model.Location, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := toObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+	return findLocation(ctx, objID)
+}
+
+func findLocation(ctx context.Context, id primitive.ObjectID) (*model.Location, error) {
+	var location model.Location
+	if err := locationCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&location); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrLocationNotFound
+		}
+		return nil, err
+	}
+	return &location, nil
+}
+
+// 🌍 Direct children of a location, or the countries when parentID is empty
+func ListChildLocations(parentID string) ([]model.Location, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"parent_id": nil}
+	if parentID != "" {
+		objID, err := toObjectID(parentID)
+		if err != nil {
+			return nil, err
+		}
+		filter["parent_id"] = objID
+	}
+
+	cursor, err := locationCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	locations := []model.Location{}
+	if err := cursor.All(ctx, &locations); err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+// 🔎 Locations whose name or alias starts with q, optionally limited to
+// some levels and to places inside within
+func AutocompleteLocations(q string, types []string, within string, limit int) ([]model.Location, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prefix := NormalizeAreaName(q)
+	if prefix == "" {
+		return nil, &FilterError{Err: errors.New("q is required")}
+	}
+	if limit <= 0 {
+		limit = defaultAutocompleteLimit
+	}
+	if limit > maxAutocompleteLimit {
+		limit = maxAutocompleteLimit
+	}
+
+	filter := bson.M{"search_terms": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)}}
+	if len(types) > 0 {
+		for _, t := range types {
+			if _, ok := model.LocationLevels[t]; !ok {
+				return nil, &FilterError{Err: fmt.Errorf("unknown location type %q", t)}
+			}
+		}
+		filter["type"] = bson.M{"$in": types}
+	}
+	if within != "" {
+		withinID, err := toObjectID(within)
+		if err != nil {
+			return nil, &FilterError{Err: errors.New("invalid within ID")}
+		}
+		filter["ancestors"] = withinID
+	}
+
+	cursor, err := locationCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "name", Value: 1}}).
+		SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	locations := []model.Location{}
+	if err := cursor.All(ctx, &locations); err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+// matchLocationName finds the one location whose name or alias equals the
+// free-text area, also accepting "name parent" forms such as "Yaba, Lagos".
+// Nil when there is no match or it is ambiguous.
+func matchLocationName(ctx context.Context, area string) (*model.Location, error) {
+	normalized := NormalizeAreaName(area)
+	if normalized == "" {
+		return nil, nil
+	}
+	tokens := strings.Fields(normalized)
+
+	// Try the longest leading phrase first: "yaba lagos" -> "yaba lagos", "yaba"
+	for n := len(tokens); n > 0; n-- {
+		head := strings.Join(tokens[:n], " ")
+		cursor, err := locationCollection.Find(ctx, bson.M{"search_terms": head}, options.Find().SetLimit(20))
+		if err != nil {
+			return nil, err
+		}
+		var candidates []model.Location
+		if err := cursor.All(ctx, &candidates); err != nil {
+			return nil, err
+		}
+
+		// Remaining words must name the location's parents
+		var matched []model.Location
+		for _, c := range candidates {
+			if containsTokens(NormalizeAreaName(c.Path), tokens[n:]) {
+				matched = append(matched, c)
+			}
+		}
+		if len(matched) == 1 {
+			return &matched[0], nil
+		}
+		if len(matched) > 1 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+func containsTokens(text string, tokens []string) bool {
+	present := map[string]bool{}
+	for _, t := range strings.Fields(text) {
+		present[t] = true
+	}
+	for _, t := range tokens {
+		if !present[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// applyProductLocation links a new product to its location. An explicit
+// location ID wins and fills in the area; otherwise an exact match on the
+// free-text area is used when there is exactly one.
+func applyProductLocation(ctx context.Context, product *model.Product) error {
+	var location *model.Location
+	var err error
+	if product.LocationID != nil {
+		if location, err = findLocation(ctx, *product.LocationID); err != nil {
+			return err
+		}
+	} else if location, err = matchLocationName(ctx, product.Area); err != nil || location == nil {
+		return err
+	}
+
+	product.LocationID = &location.ID
+	product.LocationPath = location.Lineage()
+	if strings.TrimSpace(product.Area) == "" {
+		product.Area = location.Name
+	}
+	return nil
+}
+
+// locationUpdate turns a "location_id" or free-text "area" change into the
+// location fields to set, and those to unset when a new area matches no
+// location
+func locationUpdate(ctx context.Context, fields map[string]interface{}) (unset bson.M, err error) {
+	if raw, ok := fields["location_id"]; ok {
+		id, isID := raw.(primitive.ObjectID)
+		if !isID {
+			text, _ := raw.(string)
+			if id, err = primitive.ObjectIDFromHex(text); err != nil {
+				return nil, &FilterError{Err: errors.New("invalid location ID")}
+			}
+		}
+		location, err := findLocation(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		fields["location_id"] = location.ID
+		fields["location_path"] = location.Lineage()
+		if _, hasArea := fields["area"]; !hasArea {
+			fields["area"] = location.Name
+		}
+		return nil, nil
+	}
+
+	area, ok := fields["area"].(string)
+	if !ok {
+		return nil, nil
+	}
+	location, err := matchLocationName(ctx, area)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		return bson.M{"location_id": "", "location_path": ""}, nil
+	}
+	fields["location_id"] = location.ID
+	fields["location_path"] = location.Lineage()
+	return nil, nil
+}
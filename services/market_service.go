@@ -0,0 +1,412 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var marketCollection *mongo.Collection
+
+var ErrMarketNotFound = errors.New("market not found")
+
+// marketDirectory caches every market so "open now" can be answered for a
+// listing without a lookup per product. Market edits reload it.
+type marketDirectory struct {
+	mu      sync.RWMutex
+	markets map[primitive.ObjectID]model.Market
+}
+
+var markets = &marketDirectory{markets: map[primitive.ObjectID]model.Market{}}
+
+func (d *marketDirectory) get(id primitive.ObjectID) (model.Market, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	m, ok := d.markets[id]
+	return m, ok
+}
+
+func InitMarketService() {
+	marketCollection = config.DB.Collection("markets")
+	ensureIndexes(marketCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "location_path", Value: 1}, {Key: "name", Value: 1}}},
+		{Keys: bson.D{{Key: "geo", Value: "2dsphere"}}},
+	})
+	ensureIndexes(priceReportCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "market_id", Value: 1}, {Key: "item_id", Value: 1}, {Key: "observed_at", Value: -1}}},
+	})
+	ensureIndexes(productCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "market_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+	})
+	if err := reloadMarkets(); err != nil {
+		log.Printf("⚠️ Failed to load markets: %v", err)
+	}
+}
+
+func reloadMarkets() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := marketCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var all []model.Market
+	if err := cursor.All(ctx, &all); err != nil {
+		return err
+	}
+
+	byID := make(map[primitive.ObjectID]model.Market, len(all))
+	for _, m := range all {
+		byID[m.ID] = m
+	}
+	markets.mu.Lock()
+	markets.markets = byID
+	markets.mu.Unlock()
+	return nil
+}
+
+// marketZone is the time zone a market's opening hours are written in
+func marketZone(m model.Market) *time.Location {
+	if m.Timezone != "" {
+		if zone, err := time.LoadLocation(m.Timezone); err == nil {
+			return zone
+		}
+	}
+	return config.MarketTimezone
+}
+
+// OpenMarketIDs lists the markets open at t
+func OpenMarketIDs(t time.Time) []primitive.ObjectID {
+	markets.mu.RLock()
+	defer markets.mu.RUnlock()
+	open := []primitive.ObjectID{}
+	for id, m := range markets.markets {
+		if m.IsOpenAt(t, marketZone(m)) {
+			open = append(open, id)
+		}
+	}
+	return open
+}
+
+// marketOpenAt reports whether a known market is open at t
+func marketOpenAt(id primitive.ObjectID, t time.Time) bool {
+	m, ok := markets.get(id)
+	return ok && m.IsOpenAt(t, marketZone(m))
+}
+
+// MarketView is a market with whether it is open right now
+type MarketView struct {
+	model.Market
+	OpenNow bool `json:"open_now"`
+}
+
+func viewMarket(m model.Market, now time.Time) MarketView {
+	return MarketView{Market: m, OpenNow: m.IsOpenAt(now, marketZone(m))}
+}
+
+type MarketInput struct {
+	Name       string
+	Type       string
+	Address    string
+	LocationID string
+	Lat, Lng   *float64
+	Hours      []model.OpeningHours
+	Timezone   string
+}
+
+// validate normalizes the input and resolves its location
+func (in *MarketInput) validate(ctx context.Context) (*model.Location, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		return nil, &FilterError{Err: errors.New("name is required")}
+	}
+	if !model.MarketTypes[in.Type] {
+		return nil, &FilterError{Err: errors.New("type must be open_market, supermarket or roadside")}
+	}
+	if in.Timezone != "" {
+		if _, err := time.LoadLocation(in.Timezone); err != nil {
+			return nil, &FilterError{Err: fmt.Errorf("unknown time zone %q", in.Timezone)}
+		}
+	}
+	if (in.Lat == nil) != (in.Lng == nil) {
+		return nil, &FilterError{Err: errors.New("lat and lng must be given together")}
+	}
+	if in.Lat != nil {
+		if err := ValidateCoordinates(*in.Lat, *in.Lng); err != nil {
+			return nil, &FilterError{Err: err}
+		}
+	}
+
+	if in.Hours == nil {
+		in.Hours = []model.OpeningHours{}
+	}
+	for i, window := range in.Hours {
+		if _, err := model.ParseClock(window.Open); err != nil {
+			return nil, &FilterError{Err: err}
+		}
+		if _, err := model.ParseClock(window.Close); err != nil {
+			return nil, &FilterError{Err: err}
+		}
+		if len(window.Days) == 0 {
+			return nil, &FilterError{Err: errors.New("every opening window needs at least one day")}
+		}
+		for j, day := range window.Days {
+			day = strings.ToLower(strings.TrimSpace(day))
+			if len(day) > 3 {
+				day = day[:3]
+			}
+			known := false
+			for _, weekday := range model.Weekdays {
+				known = known || day == weekday
+			}
+			if !known {
+				return nil, &FilterError{Err: fmt.Errorf("unknown day %q", window.Days[j])}
+			}
+			in.Hours[i].Days[j] = day
+		}
+	}
+
+	if in.LocationID == "" {
+		return nil, nil
+	}
+	objID, err := primitive.ObjectIDFromHex(in.LocationID)
+	if err != nil {
+		return nil, &FilterError{Err: errors.New("invalid location_id")}
+	}
+	location, err := findLocation(ctx, objID)
+	if err != nil {
+		return nil, &FilterError{Err: err}
+	}
+	return location, nil
+}
+
+func (in MarketInput) apply(m *model.Market, location *model.Location) {
+	m.Name, m.Type, m.Address = in.Name, in.Type, strings.TrimSpace(in.Address)
+	m.Hours, m.Timezone = in.Hours, in.Timezone
+	m.LocationID, m.LocationPath = nil, nil
+	if location != nil {
+		m.LocationID = &location.ID
+		m.LocationPath = location.Lineage()
+	}
+	m.Geo = nil
+	if in.Lat != nil {
+		m.Geo = model.NewGeoPoint(*in.Lat, *in.Lng)
+	}
+	m.UpdatedAt = time.Now()
+}
+
+// ✅ Add a market
+func CreateMarket(input MarketInput, actorID primitive.ObjectID) (*MarketView, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	location, err := input.validate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	market := model.Market{ID: primitive.NewObjectID(), CreatedBy: actorID, CreatedAt: time.Now()}
+	input.apply(&market, location)
+
+	if _, err := marketCollection.InsertOne(ctx, market); err != nil {
+		return nil, err
+	}
+	if err := reloadMarkets(); err != nil {
+		return nil, err
+	}
+	view := viewMarket(market, time.Now())
+	return &view, nil
+}
+
+// ✏️ Replace a market's details
+func UpdateMarket(id string, input MarketInput) (*MarketView, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := toObjectID(id)
+	if err != nil {
+		return nil, ErrMarketNotFound
+	}
+	market, ok := markets.get(objID)
+	if !ok {
+		return nil, ErrMarketNotFound
+	}
+	location, err := input.validate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	input.apply(&market, location)
+
+	if _, err := marketCollection.ReplaceOne(ctx, bson.M{"_id": objID}, market); err != nil {
+		return nil, err
+	}
+	if err := reloadMarkets(); err != nil {
+		return nil, err
+	}
+	view := viewMarket(market, time.Now())
+	return &view, nil
+}
+
+// ✅ Get a market by ID
+func GetMarket(id string) (*MarketView, error) {
+	objID, err := toObjectID(id)
+	if err != nil {
+		return nil, ErrMarketNotFound
+	}
+	market, ok := markets.get(objID)
+	if !ok {
+		return nil, ErrMarketNotFound
+	}
+	view := viewMarket(market, time.Now())
+	return &view, nil
+}
+
+// 🏪 Markets filtered by location (including everything inside it), type
+// and whether they are open now, ordered by name
+func ListMarkets(locationID primitive.ObjectID, marketType string, openNow bool) []MarketView {
+	now := time.Now()
+	markets.mu.RLock()
+	views := []MarketView{}
+	for _, m := range markets.markets {
+		if marketType != "" && m.Type != marketType {
+			continue
+		}
+		if !locationID.IsZero() && !containsID(m.LocationPath, locationID) {
+			continue
+		}
+		view := viewMarket(m, now)
+		if openNow && !view.OpenNow {
+			continue
+		}
+		views = append(views, view)
+	}
+	markets.mu.RUnlock()
+
+	sort.Slice(views, func(i, j int) bool { return views[i].Name < views[j].Name })
+	return views
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// MarketPrice is the latest report for one item at a market
+type MarketPrice struct {
+	ItemID     primitive.ObjectID `json:"item_id" bson:"_id"`
+	ItemName   string             `json:"item_name" bson:"item_name"`
+	Price      model.Money        `json:"price" bson:"price"`
+	Status     string             `json:"status" bson:"status"`
+	ProductID  primitive.ObjectID `json:"product_id,omitempty" bson:"product_id,omitempty"`
+	ObservedAt time.Time          `json:"observed_at" bson:"observed_at"`
+}
+
+// 🏷️ The most recent public price for every item reported at a market,
+// ordered by item name. Unreviewed reports and those of deleted or hidden
+// listings are skipped, so an older public price shows through instead.
+func GetMarketLatestPrices(id string) ([]MarketPrice, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := toObjectID(id)
+	if err != nil {
+		return nil, ErrMarketNotFound
+	}
+	if _, ok := markets.get(objID); !ok {
+		return nil, ErrMarketNotFound
+	}
+
+	cursor, err := priceReportCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"market_id": objID, "status": bson.M{"$nin": excludedReportStatuses}}}},
+		// Older reports may belong to a listing deleted before removals were
+		// marked on reports, so the listing is checked too
+		{{Key: "$lookup", Value: bson.M{
+			"from":         productCollection.Name(),
+			"localField":   "product_id",
+			"foreignField": "_id",
+			"pipeline":     bson.A{bson.M{"$project": bson.M{"deleted_at": 1, "hidden": 1}}},
+			"as":           "listing",
+		}}},
+		{{Key: "$match", Value: bson.M{"listing.deleted_at": nil, "listing.hidden": bson.M{"$ne": true}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "item_id", Value: 1}, {Key: "observed_at", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         "$item_id",
+			"price":       bson.M{"$first": "$price"},
+			"status":      bson.M{"$first": "$status"},
+			"product_id":  bson.M{"$first": "$product_id"},
+			"observed_at": bson.M{"$first": "$observed_at"},
+		}}},
+		{{Key: "$lookup", Value: bson.M{"from": itemCollection.Name(), "localField": "_id", "foreignField": "_id", "as": "item"}}},
+		{{Key: "$set", Value: bson.M{"item_name": bson.M{"$first": "$item.name"}}}},
+		{{Key: "$project", Value: bson.M{"item": 0}}},
+		{{Key: "$sort", Value: bson.D{{Key: "item_name", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	prices := []MarketPrice{}
+	if err := cursor.All(ctx, &prices); err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// applyProductMarket checks a product's market and fills in the location
+// and coordinates the market already knows
+func applyProductMarket(product *model.Product) error {
+	if product.MarketID == nil {
+		return nil
+	}
+	market, ok := markets.get(*product.MarketID)
+	if !ok {
+		return ErrMarketNotFound
+	}
+	if product.LocationID == nil && market.LocationID != nil {
+		product.LocationID = market.LocationID
+	}
+	if product.Location == nil && market.Geo != nil {
+		product.Location = market.Geo
+	}
+	return nil
+}
+
+// marketUpdate validates a "market_id" change and links the market's
+// location when the update does not set one itself
+func marketUpdate(fields map[string]interface{}) error {
+	raw, ok := fields["market_id"]
+	if !ok {
+		return nil
+	}
+	text, _ := raw.(string)
+	id, err := primitive.ObjectIDFromHex(text)
+	if err != nil {
+		return &FilterError{Err: errors.New("invalid market_id")}
+	}
+	market, ok := markets.get(id)
+	if !ok {
+		return ErrMarketNotFound
+	}
+	fields["market_id"] = id
+	_, hasLocation := fields["location_id"]
+	_, hasArea := fields["area"]
+	if !hasLocation && !hasArea && market.LocationID != nil {
+		fields["location_id"] = *market.LocationID
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func withMarketTimezone(t *testing.T, zone *time.Location) {
+	saved := config.MarketTimezone
+	config.MarketTimezone = zone
+	t.Cleanup(func() { config.MarketTimezone = saved })
+}
+
+func TestMarketZone(t *testing.T) {
+	wat := time.FixedZone("WAT", 60*60)
+	withMarketTimezone(t, wat)
+
+	tests := []struct {
+		timezone string
+		want     string
+	}{
+		{"", "WAT"},
+		{"UTC", "UTC"},
+		{"Not/AZone", "WAT"},
+	}
+
+	for _, tt := range tests {
+		if got := marketZone(model.Market{Timezone: tt.timezone}).String(); got != tt.want {
+			t.Errorf("marketZone(%q) = %s, want %s", tt.timezone, got, tt.want)
+		}
+	}
+}
+
+func TestOpenMarketIDs(t *testing.T) {
+	withMarketTimezone(t, time.FixedZone("WAT", 60*60))
+	lagos, utc := primitive.NewObjectID(), primitive.NewObjectID()
+	hours := []model.OpeningHours{{Days: []string{"mon"}, Open: "08:00", Close: "18:00"}}
+
+	markets.mu.Lock()
+	saved := markets.markets
+	markets.markets = map[primitive.ObjectID]model.Market{
+		lagos: {ID: lagos, Hours: hours},
+		utc:   {ID: utc, Hours: hours, Timezone: "UTC"},
+	}
+	markets.mu.Unlock()
+	t.Cleanup(func() {
+		markets.mu.Lock()
+		markets.markets = saved
+		markets.mu.Unlock()
+	})
+
+	// Monday 07:30 UTC is 08:30 in the default zone
+	open := OpenMarketIDs(time.Date(2024, 1, 1, 7, 30, 0, 0, time.UTC))
+	if len(open) != 1 || open[0] != lagos {
+		t.Errorf("open = %v, want only %s", open, lagos.Hex())
+	}
+}
+
+func TestMarketInputValidate(t *testing.T) {
+	float := func(v float64) *float64 { return &v }
+	valid := func() MarketInput {
+		return MarketInput{Name: " Mile 12 ", Type: model.MarketOpen}
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(*MarketInput)
+		wantErr string
+	}{
+		{"valid", func(in *MarketInput) {}, ""},
+		{"blank name", func(in *MarketInput) { in.Name = "  " }, "name is required"},
+		{"unknown type", func(in *MarketInput) { in.Type = "mall" }, "type must be"},
+		{"unknown time zone", func(in *MarketInput) { in.Timezone = "Lagos" }, "unknown time zone"},
+		{"lat without lng", func(in *MarketInput) { in.Lat = float(6.5) }, "together"},
+		{"lat out of range", func(in *MarketInput) { in.Lat, in.Lng = float(91), float(3.3) }, "lat must be"},
+		{"lng out of range", func(in *MarketInput) { in.Lat, in.Lng = float(6.5), float(-181) }, "lng must be"},
+		{"NaN lat", func(in *MarketInput) { in.Lat, in.Lng = float(math.NaN()), float(3.3) }, "lat must be"},
+		{"NaN lng", func(in *MarketInput) { in.Lat, in.Lng = float(6.5), float(math.NaN()) }, "lng must be"},
+		{"infinite lat", func(in *MarketInput) { in.Lat, in.Lng = float(math.Inf(1)), float(3.3) }, "lat must be"},
+		{"bad clock", func(in *MarketInput) {
+			in.Hours = []model.OpeningHours{{Days: []string{"mon"}, Open: "8am", Close: "18:00"}}
+		}, "invalid time"},
+		{"no days", func(in *MarketInput) {
+			in.Hours = []model.OpeningHours{{Open: "08:00", Close: "18:00"}}
+		}, "at least one day"},
+		{"unknown day", func(in *MarketInput) {
+			in.Hours = []model.OpeningHours{{Days: []string{"mo"}, Open: "08:00", Close: "18:00"}}
+		}, "unknown day"},
+		{"bad location id", func(in *MarketInput) { in.LocationID = "yaba" }, "invalid location_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := valid()
+			tt.edit(&in)
+			_, err := in.validate(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("err = %v", err)
+				}
+				return
+			}
+			var filterErr *FilterError
+			if !errors.As(err, &filterErr) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want a FilterError containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMarketInputValidateNormalizes(t *testing.T) {
+	in := MarketInput{
+		Name: "  Mile 12 ",
+		Type: model.MarketOpen,
+		Hours: []model.OpeningHours{
+			{Days: []string{"Monday", " TUE ", "wednesday", "thu"}, Open: "08:00", Close: "18:00"},
+			{Days: []string{"Saturday"}, Open: "22:00", Close: "02:00"},
+		},
+	}
+	if _, err := in.validate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if in.Name != "Mile 12" {
+		t.Errorf("name = %q", in.Name)
+	}
+	if got := strings.Join(in.Hours[0].Days, ","); got != "mon,tue,wed,thu" {
+		t.Errorf("days = %s, want mon,tue,wed,thu", got)
+	}
+	if got := strings.Join(in.Hours[1].Days, ","); got != "sat" {
+		t.Errorf("days = %s, want sat", got)
+	}
+
+	// Normalized days are what IsOpenAt matches: Sunday 01:00 is still
+	// Saturday night
+	m := model.Market{Hours: in.Hours}
+	sunday := time.Date(2024, 1, 7, 1, 0, 0, 0, time.UTC)
+	if !m.IsOpenAt(sunday, time.UTC) {
+		t.Error("closed at 01:00 after a Saturday night opening")
+	}
+
+	empty := MarketInput{Name: "Shoprite", Type: model.MarketSupermarket}
+	if _, err := empty.validate(context.Background()); err != nil || empty.Hours == nil {
+		t.Errorf("hours = %v, %v, want an empty list", empty.Hours, err)
+	}
+}
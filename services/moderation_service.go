@@ -0,0 +1,440 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	flagCollection             *mongo.Collection
+	moderationActionCollection *mongo.Collection
+)
+
+var (
+	ErrInvalidFlagReason = errors.New("invalid reason code")
+	ErrAlreadyFlagged    = errors.New("you have already reported this product")
+	ErrInvalidModAction  = errors.New("invalid moderation action")
+	ErrSuspended         = errors.New("your account is suspended")
+	ErrUserNotFound      = errors.New("user not found")
+)
+
+func InitModerationService() {
+	flagCollection = config.DB.Collection("moderation_flags")
+	moderationActionCollection = config.DB.Collection("moderation_actions")
+
+	ensureIndexes(flagCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "product_id", Value: 1}}},
+		{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "reporter_id", Value: 1}, {Key: "status", Value: 1}}},
+	})
+	ensureIndexes(moderationActionCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "target_user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+	})
+}
+
+// EnsureCanContribute rejects writes from suspended accounts. A suspension
+// with an end date lapses on its own.
+func EnsureCanContribute(userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var user model.User
+	err := userCollection.FindOne(ctx, bson.M{"_id": userID}, options.FindOne().SetProjection(bson.M{"status": 1, "suspendedUntil": 1})).Decode(&user)
+	if err != nil {
+		// Unknown users are caught by ownership checks elsewhere
+		return nil
+	}
+	if user.Status != model.UserStatusSuspended {
+		return nil
+	}
+	if user.SuspendedUntil != nil && user.SuspendedUntil.Before(time.Now()) {
+		return nil
+	}
+	return ErrSuspended
+}
+
+// 🚩 Report a listing to the moderators
+func FlagProduct(productID, reporterID, reason, note string) (*model.ModerationFlag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if !model.FlagReasons[reason] {
+		return nil, ErrInvalidFlagReason
+	}
+
+	product, err := GetProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	reporterObjID, err := toObjectID(reporterID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+	if err := EnsureCanContribute(reporterObjID); err != nil {
+		return nil, err
+	}
+
+	count, err := flagCollection.CountDocuments(ctx, bson.M{
+		"product_id":  product.ID,
+		"reporter_id": reporterObjID,
+		"status":      model.FlagStatusOpen,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrAlreadyFlagged
+	}
+
+	flag := model.ModerationFlag{
+		ID:         primitive.NewObjectID(),
+		ProductID:  product.ID,
+		ReporterID: reporterObjID,
+		Reason:     reason,
+		Note:       strings.TrimSpace(note),
+		Status:     model.FlagStatusOpen,
+		CreatedAt:  time.Now(),
+	}
+	if _, err := flagCollection.InsertOne(ctx, flag); err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
+
+type QueueFlag struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	ReporterID primitive.ObjectID `bson:"reporter_id" json:"reporter_id"`
+	Reason     string             `bson:"reason" json:"reason"`
+	Note       string             `bson:"note" json:"note,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// FlaggedProduct is one entry of the moderation queue
+type FlaggedProduct struct {
+	ProductID    primitive.ObjectID `bson:"_id" json:"product_id"`
+	Product      *model.Product     `bson:"product" json:"product"`
+	FlagCount    int                `bson:"flag_count" json:"flag_count"`
+	Reasons      map[string]int     `bson:"-" json:"reasons"`
+	Flags        []QueueFlag        `bson:"flags" json:"flags"`
+	FirstFlagged time.Time          `bson:"first_flagged" json:"first_flagged"`
+	LastFlagged  time.Time          `bson:"last_flagged" json:"last_flagged"`
+}
+
+// 🗂️ Open flags grouped by product, most-flagged first
+func GetModerationQueue(limit int) ([]FlaggedProduct, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if limit <= 0 || limit > config.MaxProductPageSize {
+		limit = config.ProductPageSize
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": model.FlagStatusOpen}}},
+		{{Key: "$sort", Value: bson.M{"created_at": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           "$product_id",
+			"flag_count":    bson.M{"$sum": 1},
+			"first_flagged": bson.M{"$min": "$created_at"},
+			"last_flagged":  bson.M{"$max": "$created_at"},
+			"flags": bson.M{"$push": bson.M{
+				"_id":         "$_id",
+				"reporter_id": "$reporter_id",
+				"reason":      "$reason",
+				"note":        "$note",
+				"created_at":  "$created_at",
+			}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "flag_count", Value: -1}, {Key: "first_flagged", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         productCollection.Name(),
+			"localField":   "_id",
+			"foreignField": "_id",
+			"as":           "product",
+		}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$product", "preserveNullAndEmptyArrays": true}}},
+	}
+
+	cursor, err := flagCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	queue := []FlaggedProduct{}
+	if err := cursor.All(ctx, &queue); err != nil {
+		return nil, err
+	}
+	for i := range queue {
+		queue[i].Reasons = map[string]int{}
+		for _, f := range queue[i].Flags {
+			queue[i].Reasons[f.Reason]++
+		}
+	}
+	return queue, nil
+}
+
+// 🕵️ Price reports waiting for review (held or flagged as suspect)
+func GetReviewQueue(limit int) ([]model.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if limit <= 0 || limit > config.MaxProductPageSize {
+		limit = config.ProductPageSize
+	}
+
+	cursor, err := productCollection.Find(ctx,
+		bson.M{"status": bson.M{"$in": unreviewedStatuses}, "deleted_at": nil},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	products := []model.Product{}
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func logModerationAction(ctx context.Context, entry model.ModerationAction) error {
+	entry.ID = primitive.NewObjectID()
+	entry.CreatedAt = time.Now()
+	_, err := moderationActionCollection.InsertOne(ctx, entry)
+	return err
+}
+
+// resolveFlags closes every open flag on a product
+func resolveFlags(ctx context.Context, productID, moderatorID primitive.ObjectID, status string) error {
+	now := time.Now()
+	_, err := flagCollection.UpdateMany(ctx,
+		bson.M{"product_id": productID, "status": model.FlagStatusOpen},
+		bson.M{"$set": bson.M{"status": status, "resolved_by": moderatorID, "resolved_at": now}},
+	)
+	return err
+}
+
+// reportStatusFor is the status a listing's price reports should carry: its
+// review status while it is listed, removed while it is hidden or deleted
+func reportStatusFor(product model.Product) string {
+	switch {
+	case product.Hidden, product.DeletedAt != nil:
+		return model.ReportStatusRemoved
+	case product.Status == "":
+		return model.ReportStatusPublished
+	}
+	return product.Status
+}
+
+func setPriceReportStatus(ctx context.Context, productID primitive.ObjectID, status string) error {
+	_, err := priceReportCollection.UpdateMany(ctx, bson.M{"product_id": productID}, bson.M{"$set": bson.M{"status": status}})
+	return err
+}
+
+// setReportStatus records a review decision on a listing and its reports
+func setReportStatus(ctx context.Context, product *model.Product, status string) error {
+	if _, err := productCollection.UpdateOne(ctx, bson.M{"_id": product.ID}, bson.M{"$set": bson.M{"status": status}}); err != nil {
+		return err
+	}
+	product.Status = status
+	return setPriceReportStatus(ctx, product.ID, reportStatusFor(*product))
+}
+
+// 🛡️ Apply a moderator decision to a product, including one its owner
+// deleted so its flags can still be dismissed. Every action is logged;
+// hiding a hidden product or restoring a visible one changes nothing.
+func ModerateProduct(productID, moderatorID, action, note string) (*model.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	product, err := findAnyProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	moderatorObjID, err := toObjectID(moderatorID)
+	if err != nil {
+		return nil, errors.New("invalid moderator ID")
+	}
+
+	var result *mongo.UpdateResult
+	switch action {
+	case model.ModActionHide:
+		result, err = productCollection.UpdateOne(ctx, bson.M{"_id": product.ID, "hidden": bson.M{"$ne": true}}, bson.M{"$set": bson.M{"hidden": true}})
+		if err != nil {
+			return nil, err
+		}
+		if result.ModifiedCount == 0 {
+			product.Hidden = true
+			return product, nil
+		}
+		recordRevision(ctx, product.ID, moderatorObjID, model.RevisionModerate, []model.FieldChange{
+			{Field: "hidden", Old: false, New: true},
+		})
+		product.Hidden = true
+		if err := setPriceReportStatus(ctx, product.ID, reportStatusFor(*product)); err != nil {
+			return nil, err
+		}
+		err = resolveFlags(ctx, product.ID, moderatorObjID, model.FlagStatusResolved)
+		applyReputationEventAsync(product.UserID, ReputationEvent{ModeratorPenalties: 1})
+	case model.ModActionRestore:
+		result, err = productCollection.UpdateOne(ctx, bson.M{"_id": product.ID, "hidden": true}, bson.M{"$unset": bson.M{"hidden": ""}})
+		if err != nil {
+			return nil, err
+		}
+		if result.ModifiedCount == 0 {
+			product.Hidden = false
+			return product, nil
+		}
+		recordRevision(ctx, product.ID, moderatorObjID, model.RevisionModerate, []model.FieldChange{
+			{Field: "hidden", Old: true, New: false},
+		})
+		product.Hidden = false
+		err = setPriceReportStatus(ctx, product.ID, reportStatusFor(*product))
+	case model.ModActionApprove:
+		previous := product.Status
+		if err = setReportStatus(ctx, product, model.ReportStatusPublished); err == nil {
+			recordRevision(ctx, product.ID, moderatorObjID, model.RevisionModerate, []model.FieldChange{
+				{Field: "status", Old: previous, New: model.ReportStatusPublished},
+			})
+		}
+	case model.ModActionDelete:
+		// Soft delete; the purge job removes the document and image later
+		if err := SoftDeleteProduct(productID, moderatorID); err != nil {
+			return nil, err
+		}
+		err = resolveFlags(ctx, product.ID, moderatorObjID, model.FlagStatusResolved)
+		applyReputationEventAsync(product.UserID, ReputationEvent{ModeratorPenalties: 1})
+	case model.ModActionDismiss:
+		err = resolveFlags(ctx, product.ID, moderatorObjID, model.FlagStatusDismissed)
+	default:
+		return nil, ErrInvalidModAction
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry := model.ModerationAction{
+		ModeratorID:  moderatorObjID,
+		Action:       action,
+		ProductID:    &product.ID,
+		TargetUserID: &product.UserID,
+		Note:         strings.TrimSpace(note),
+	}
+	if err := logModerationAction(ctx, entry); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// 🛡️ Warn, suspend or reinstate a contributor. suspendDays of 0 suspends
+// until a moderator reinstates the account.
+func ModerateUser(userID, moderatorID, action, note string, suspendDays int) (*PublicProfile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userObjID, err := toObjectID(userID)
+	if err != nil {
+		return nil, err
+	}
+	moderatorObjID, err := toObjectID(moderatorID)
+	if err != nil {
+		return nil, errors.New("invalid moderator ID")
+	}
+
+	var update bson.M
+	switch action {
+	case model.ModActionWarn:
+		update = bson.M{"$inc": bson.M{"warnings": 1}}
+	case model.ModActionSuspend:
+		set := bson.M{"status": model.UserStatusSuspended}
+		if suspendDays > 0 {
+			set["suspendedUntil"] = time.Now().AddDate(0, 0, suspendDays)
+			update = bson.M{"$set": set}
+		} else {
+			update = bson.M{"$set": set, "$unset": bson.M{"suspendedUntil": ""}}
+		}
+	case model.ModActionUnsuspend:
+		update = bson.M{"$set": bson.M{"status": model.UserStatusActive}, "$unset": bson.M{"suspendedUntil": ""}}
+	default:
+		return nil, ErrInvalidModAction
+	}
+
+	result, err := userCollection.UpdateOne(ctx, bson.M{"_id": userObjID}, update)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	if action == model.ModActionWarn || action == model.ModActionSuspend {
+		if err := ApplyReputationEvent(ctx, userObjID, ReputationEvent{ModeratorPenalties: 1}); err != nil {
+			log.Printf("⚠️ Failed to update reputation for %s: %v", userID, err)
+		}
+	}
+
+	entry := model.ModerationAction{
+		ModeratorID:  moderatorObjID,
+		Action:       action,
+		TargetUserID: &userObjID,
+		Note:         strings.TrimSpace(note),
+	}
+	if err := logModerationAction(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return GetPublicProfile(userID)
+}
+
+// 📜 Moderation log, newest first, optionally for one product or user
+func ListModerationActions(productID, userID string, limit int) ([]model.ModerationAction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if productID != "" {
+		objID, err := toObjectID(productID)
+		if err != nil {
+			return nil, err
+		}
+		filter["product_id"] = objID
+	}
+	if userID != "" {
+		objID, err := toObjectID(userID)
+		if err != nil {
+			return nil, err
+		}
+		filter["target_user_id"] = objID
+	}
+	if limit <= 0 || limit > config.MaxProductPageSize {
+		limit = config.ProductPageSize
+	}
+
+	cursor, err := moderationActionCollection.Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	actions := []model.ModerationAction{}
+	if err := cursor.All(ctx, &actions); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
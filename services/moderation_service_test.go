@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestReportStatusFor(t *testing.T) {
+	deletedAt := time.Now()
+	tests := []struct {
+		name    string
+		product model.Product
+		want    string
+	}{
+		{"published", model.Product{Status: model.ReportStatusPublished}, model.ReportStatusPublished},
+		{"no status", model.Product{}, model.ReportStatusPublished},
+		{"pending", model.Product{Status: model.ReportStatusPending}, model.ReportStatusPending},
+		{"suspect", model.Product{Status: model.ReportStatusSuspect}, model.ReportStatusSuspect},
+		{"hidden", model.Product{Status: model.ReportStatusPublished, Hidden: true}, model.ReportStatusRemoved},
+		{"hidden while pending", model.Product{Status: model.ReportStatusPending, Hidden: true}, model.ReportStatusRemoved},
+		{"deleted", model.Product{Status: model.ReportStatusPublished, DeletedAt: &deletedAt}, model.ReportStatusRemoved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reportStatusFor(tt.product); got != tt.want {
+				t.Errorf("reportStatusFor = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemovedReportsAreExcludedFromStats(t *testing.T) {
+	for _, status := range []string{model.ReportStatusRemoved, model.ReportStatusPending, model.ReportStatusSuspect} {
+		found := false
+		for _, excluded := range excludedReportStatuses {
+			found = found || excluded == status
+		}
+		if !found {
+			t.Errorf("status %q is not excluded from aggregates", status)
+		}
+	}
+}
+
+// TestModerateProductIsIdempotent needs a MongoDB server in TEST_MONGO_URI
+func TestModerateProductIsIdempotent(t *testing.T) {
+	testDatabase(t)
+	ctx := context.Background()
+
+	owner := model.User{ID: primitive.NewObjectID(), Name: "Seller"}
+	if _, err := userCollection.InsertOne(ctx, owner); err != nil {
+		t.Fatal(err)
+	}
+	product := model.Product{ID: primitive.NewObjectID(), UserID: owner.ID, Name: "Rice", Status: model.ReportStatusPublished}
+	if _, err := productCollection.InsertOne(ctx, product); err != nil {
+		t.Fatal(err)
+	}
+	moderator := primitive.NewObjectID().Hex()
+
+	penalties := func() int {
+		var user model.User
+		if err := userCollection.FindOne(ctx, bson.M{"_id": owner.ID}).Decode(&user); err != nil {
+			t.Fatal(err)
+		}
+		return user.Reputation.ModeratorPenalties
+	}
+	waitForPenalties := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for penalties() != want && time.Now().Before(deadline) {
+			time.Sleep(20 * time.Millisecond)
+		}
+		// Give a stray second penalty time to land before checking
+		time.Sleep(100 * time.Millisecond)
+		if got := penalties(); got != want {
+			t.Fatalf("moderator penalties = %d, want %d", got, want)
+		}
+	}
+	count := func(collection *mongo.Collection, filter bson.M) int64 {
+		n, err := collection.CountDocuments(ctx, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := ModerateProduct(product.ID.Hex(), moderator, model.ModActionHide, "spam")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Hidden {
+			t.Errorf("hide %d returned a visible product", i+1)
+		}
+	}
+	waitForPenalties(1)
+	if n := count(moderationActionCollection, bson.M{"action": model.ModActionHide}); n != 1 {
+		t.Errorf("logged %d hide actions, want 1", n)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := ModerateProduct(product.ID.Hex(), moderator, model.ModActionRestore, "fine"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := count(revisionCollection, bson.M{"product_id": product.ID, "action": model.RevisionModerate}); n != 2 {
+		t.Errorf("recorded %d moderation revisions, want one hide and one restore", n)
+	}
+
+	// Flags on a listing its owner deleted can still be dismissed
+	deletedAt := time.Now()
+	if _, err := productCollection.UpdateOne(ctx, bson.M{"_id": product.ID}, bson.M{"$set": bson.M{"deleted_at": deletedAt}}); err != nil {
+		t.Fatal(err)
+	}
+	flag := model.ModerationFlag{ID: primitive.NewObjectID(), ProductID: product.ID, ReporterID: primitive.NewObjectID(), Reason: model.FlagReasonSpam, Status: model.FlagStatusOpen}
+	if _, err := flagCollection.InsertOne(ctx, flag); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ModerateProduct(product.ID.Hex(), moderator, model.ModActionDismiss, "owner deleted it"); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(flagCollection, bson.M{"status": model.FlagStatusOpen}); n != 0 {
+		t.Errorf("%d flags still open on the deleted listing", n)
+	}
+
+	if _, err := ModerateProduct(primitive.NewObjectID().Hex(), moderator, model.ModActionHide, ""); !errors.Is(err, ErrProductNotFound) {
+		t.Errorf("unknown product: err = %v, want ErrProductNotFound", err)
+	}
+}
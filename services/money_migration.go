@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const moneyMigrationBatchSize = 500
+
+// legacyPriceType matches bare numeric prices, listing each type so the
+// match stays in step with what model.LegacyMoney converts
+var legacyPriceType = bson.M{"$type": bson.A{"double", "int", "long", "decimal"}}
+
+type MoneyMigrationSkip struct {
+	Collection string      `json:"collection"`
+	ID         string      `json:"id"`
+	Price      interface{} `json:"price"`
+	Reason     string      `json:"reason"`
+}
+
+type MoneyMigrationReport struct {
+	DryRun            bool                 `json:"dry_run"`
+	Currency          string               `json:"currency"`
+	ProductsConverted int                  `json:"products_converted"`
+	ReportsConverted  int                  `json:"reports_converted"`
+	Skipped           []MoneyMigrationSkip `json:"skipped"`
+}
+
+// 💱 MigratePricesToMoney rewrites legacy numeric prices on products and
+// price reports as Money in the default currency, rounded to the nearest
+// minor unit; decimals that do not fit the minor unit are reported as
+// skipped. Documents already holding Money are not matched, so it is safe to
+// re-run. Until it has run, Money decodes legacy numbers on read.
+func MigratePricesToMoney(dryRun bool) (*MoneyMigrationReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	currency, err := model.NormalizeCurrency(config.DefaultCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("DEFAULT_CURRENCY: %w", err)
+	}
+
+	report := &MoneyMigrationReport{DryRun: dryRun, Currency: currency, Skipped: []MoneyMigrationSkip{}}
+	if report.ProductsConverted, err = migrateCollectionPrices(ctx, productCollection, currency, dryRun, report); err != nil {
+		return nil, err
+	}
+	if report.ReportsConverted, err = migrateCollectionPrices(ctx, priceReportCollection, currency, dryRun, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func migrateCollectionPrices(ctx context.Context, coll *mongo.Collection, currency string, dryRun bool, report *MoneyMigrationReport) (int, error) {
+	cursor, err := coll.Find(ctx,
+		bson.M{"price": legacyPriceType},
+		options.Find().SetProjection(bson.M{"price": 1}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	converted := 0
+	var writes []mongo.WriteModel
+	flush := func() error {
+		if len(writes) == 0 || dryRun {
+			writes = writes[:0]
+			return nil
+		}
+		_, err := coll.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		writes = writes[:0]
+		return err
+	}
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Price bson.RawValue      `bson:"price"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return converted, err
+		}
+
+		price, err := model.LegacyMoney(doc.Price, currency)
+		if err != nil {
+			report.Skipped = append(report.Skipped, MoneyMigrationSkip{
+				Collection: coll.Name(),
+				ID:         doc.ID.Hex(),
+				Price:      legacyPriceValue(doc.Price),
+				Reason:     err.Error(),
+			})
+			continue
+		}
+
+		// Match the numeric price again so a concurrent edit is not clobbered
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc.ID, "price": legacyPriceType}).
+			SetUpdate(bson.M{"$set": bson.M{"price": price}}))
+		converted++
+		if len(writes) >= moneyMigrationBatchSize {
+			if err := flush(); err != nil {
+				return converted, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return converted, err
+	}
+	return converted, flush()
+}
+
+// legacyPriceValue renders a skipped price for the report
+func legacyPriceValue(value bson.RawValue) interface{} {
+	switch value.Type {
+	case bson.TypeDouble:
+		return value.Double()
+	case bson.TypeInt32:
+		return value.Int32()
+	case bson.TypeInt64:
+		return value.Int64()
+	case bson.TypeDecimal128:
+		return value.Decimal128().String()
+	}
+	return value.String()
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Statuses that keep a report out of aggregates until it is reviewed
+var unreviewedStatuses = []string{model.ReportStatusSuspect, model.ReportStatusPending}
+
+// Statuses left out of aggregates: unreviewed reports and those of listings
+// a moderator took down
+var excludedReportStatuses = append([]string{model.ReportStatusRemoved}, unreviewedStatuses...)
+
+// maxOutlierSamples bounds how many recent reports a new price is scored against
+const maxOutlierSamples = 500
+
+const (
+	SuspectPriceWarning = "This price is far from recent reports for this item in this area. " +
+		"It is visible but excluded from price statistics until reviewed."
+	PendingReviewWarning = "Your report has been received and will be published once a moderator reviews it."
+)
+
+// PriceAssessment is the outcome of scoring a price. Median is in the
+// price's minor units.
+type PriceAssessment struct {
+	Status  string
+	Score   *float64
+	Median  float64
+	Samples int
+}
+
+// RobustScore measures how far price sits from the sample using the median
+// absolute deviation, falling back to the IQR when MAD is zero. The scale
+// never drops below 10% of the median so a tight cluster of identical prices
+// does not flag every small difference. ok is false for empty samples.
+func RobustScore(sample []float64, price float64) (score, med float64, ok bool) {
+	if len(sample) == 0 {
+		return 0, 0, false
+	}
+
+	sorted := append([]float64(nil), sample...)
+	sort.Float64s(sorted)
+	med = median(sorted)
+
+	deviations := make([]float64, len(sorted))
+	for i, p := range sorted {
+		deviations[i] = math.Abs(p - med)
+	}
+	sort.Float64s(deviations)
+
+	scale := 1.4826 * median(deviations)
+	if scale == 0 {
+		q1 := sorted[percentileRank(0.25, len(sorted))]
+		q3 := sorted[percentileRank(0.75, len(sorted))]
+		scale = (q3 - q1) / 1.349
+	}
+	if floor := 0.1 * math.Abs(med); scale < floor {
+		scale = floor
+	}
+	if scale == 0 {
+		if price == med {
+			return 0, med, true
+		}
+		return math.Inf(1), med, true
+	}
+
+	return math.Abs(price-med) / scale, med, true
+}
+
+// 🚨 Score a price against recent published reports for the same item, area
+// and currency. The report being scored is excluded when excludeProductID is
+// set.
+func assessPrice(ctx context.Context, itemID primitive.ObjectID, area string, price model.Money, excludeProductID primitive.ObjectID) PriceAssessment {
+	assessment := PriceAssessment{Status: model.ReportStatusPublished}
+
+	since := time.Now().AddDate(0, 0, -config.OutlierWindowDays)
+	filter := statsMatch(itemID, area, since)
+	filter["price.currency"] = price.Currency
+	if !excludeProductID.IsZero() {
+		filter["product_id"] = bson.M{"$ne": excludeProductID}
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"price.amount": 1}).
+		SetSort(bson.D{{Key: "observed_at", Value: -1}}).
+		SetLimit(maxOutlierSamples)
+
+	cursor, err := priceReportCollection.Find(ctx, filter, opts)
+	if err != nil {
+		log.Printf("⚠️ Failed to load reports for outlier check: %v", err)
+		return assessment
+	}
+	var rows []struct {
+		Price model.Money `bson:"price"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		log.Printf("⚠️ Failed to load reports for outlier check: %v", err)
+		return assessment
+	}
+
+	assessment.Samples = len(rows)
+	if len(rows) < config.OutlierMinSamples {
+		return assessment
+	}
+
+	sample := make([]float64, len(rows))
+	for i, r := range rows {
+		sample[i] = float64(r.Price.Amount)
+	}
+
+	score, med, _ := RobustScore(sample, float64(price.Amount))
+	rounded := math.Round(score*100) / 100
+	if math.IsInf(score, 1) {
+		rounded = math.MaxFloat64
+	}
+	assessment.Score = &rounded
+	assessment.Median = med
+	if score > config.OutlierThreshold {
+		assessment.Status = model.ReportStatusSuspect
+	}
+	return assessment
+}
+
+// PriceWarning is the message shown to a submitter whose price was flagged
+func PriceWarning(p model.Product) string {
+	switch p.Status {
+	case model.ReportStatusSuspect:
+		return SuspectPriceWarning
+	case model.ReportStatusPending:
+		return PendingReviewWarning
+	}
+	return ""
+}
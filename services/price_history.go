@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// History bucket sizes accepted on GET /items/:id/history
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+// Default look-back per bucket size when no window is given
+var defaultHistoryWindows = map[string]string{
+	BucketDay:   "30d",
+	BucketWeek:  "26w",
+	BucketMonth: "52w",
+}
+
+// HistoryPoint is one bucket of the series. Buckets without reports are
+// returned with Gap set and no median rather than being interpolated, and
+// ChangePct is only given when the preceding bucket has data.
+type HistoryPoint struct {
+	BucketStart time.Time `json:"bucket_start"`
+	Median      *float64  `json:"median"`
+	Count       int       `json:"count"`
+	ChangePct   *float64  `json:"change_pct"`
+	Gap         bool      `json:"gap"`
+}
+
+type ItemPriceHistory struct {
+	ItemID    string         `json:"item_id"`
+	Area      string         `json:"area,omitempty"`
+	Currency  string         `json:"currency"`
+	Bucket    string         `json:"bucket"`
+	Since     time.Time      `json:"since"`
+	Points    []HistoryPoint `json:"points"`
+	RatesUsed []AppliedRate  `json:"rates_used,omitempty"`
+}
+
+// truncateBucket floors t (in UTC) to the start of its bucket. Weeks start
+// on Monday.
+func truncateBucket(t time.Time, bucket string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch bucket {
+	case BucketWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case BucketMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+func nextBucket(t time.Time, bucket string) time.Time {
+	switch bucket {
+	case BucketWeek:
+		return t.AddDate(0, 0, 7)
+	case BucketMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// BuildHistory lays prices grouped by bucket start onto a continuous series
+// from since to until, marking empty buckets as gaps.
+func BuildHistory(pricesByBucket map[time.Time][]float64, bucket string, since, until time.Time) []HistoryPoint {
+	points := []HistoryPoint{}
+	var prev *HistoryPoint
+
+	for start := truncateBucket(since, bucket); !start.After(until); start = nextBucket(start, bucket) {
+		point := HistoryPoint{BucketStart: start}
+		prices := pricesByBucket[start]
+		if len(prices) == 0 {
+			point.Gap = true
+		} else {
+			median := ComputePriceStats(prices).Median
+			point.Median = &median
+			point.Count = len(prices)
+			if prev != nil && !prev.Gap && *prev.Median != 0 {
+				change := math.Round((median-*prev.Median) / *prev.Median * 10000) / 100
+				point.ChangePct = &change
+			}
+		}
+		points = append(points, point)
+		prev = &points[len(points)-1]
+	}
+	return points
+}
+
+// bucketReports groups report prices (in major units) by bucket start
+func bucketReports(reports []model.PriceReport, bucket string) map[time.Time][]float64 {
+	buckets := map[time.Time][]float64{}
+	for _, r := range reports {
+		start := truncateBucket(r.ObservedAt, bucket)
+		buckets[start] = append(buckets[start], r.Price.Major())
+	}
+	return buckets
+}
+
+func aggregateHistoryBuckets(ctx context.Context, match bson.M, bucket, currency string) (map[time.Time][]float64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		valueStage(currency),
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$dateTrunc": bson.M{
+				"date":        "$observed_at",
+				"unit":        bucket,
+				"timezone":    "UTC",
+				"startOfWeek": "monday",
+			}},
+			"prices": bson.M{"$push": "$value"},
+		}}},
+	}
+
+	cursor, err := priceReportCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Start  time.Time `bson:"_id"`
+		Prices []float64 `bson:"prices"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	buckets := map[time.Time][]float64{}
+	for _, row := range rows {
+		buckets[row.Start.UTC()] = row.Prices
+	}
+	return buckets, nil
+}
+
+// 📈 Bucketed median prices for an item. Servers without $dateTrunc
+// (MongoDB < 5.0) fall back to bucketing in Go, as do requests that need
+// reports converted into another currency.
+func GetItemPriceHistory(itemID, area, bucket, window, currency string) (*ItemPriceHistory, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	objID, err := toObjectID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if bucket == "" {
+		bucket = BucketDay
+	}
+	if _, ok := defaultHistoryWindows[bucket]; !ok {
+		return nil, &FilterError{Err: errors.New("bucket must be day, week or month")}
+	}
+	if window == "" {
+		window = defaultHistoryWindows[bucket]
+	}
+	d, err := ParseWindow(window)
+	if err != nil {
+		return nil, &FilterError{Err: err}
+	}
+
+	now := time.Now().UTC()
+	since := truncateBucket(now.Add(-d), bucket)
+	match := statsMatch(objID, area, since)
+	currency, convert, err := resolveCurrency(ctx, match, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &ItemPriceHistory{ItemID: itemID, Area: area, Currency: currency, Bucket: bucket, Since: since}
+	var buckets map[time.Time][]float64
+	if convert {
+		reports, rates, err := convertedReports(ctx, match, currency)
+		if err != nil {
+			return nil, err
+		}
+		buckets = bucketReports(reports, bucket)
+		history.RatesUsed = rates
+	} else {
+		match["price.currency"] = currency
+		buckets, err = aggregateHistoryBuckets(ctx, match, bucket, currency)
+		if err != nil {
+			log.Printf("⚠️ History aggregation failed, bucketing in Go: %v", err)
+			reports, findErr := findPriceReports(ctx, match)
+			if findErr != nil {
+				return nil, findErr
+			}
+			buckets = bucketReports(reports, bucket)
+		}
+	}
+
+	history.Points = BuildHistory(buckets, bucket, since, now)
+	return history, nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"CROWD_MARKET/model"
+)
+
+func utcDay(month time.Month, d int) time.Time {
+	return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestTruncateBucket(t *testing.T) {
+	lagos := time.FixedZone("WAT", 60*60)
+	tests := []struct {
+		t      time.Time
+		bucket string
+		want   time.Time
+	}{
+		{time.Date(2024, 3, 6, 15, 4, 5, 0, time.UTC), BucketDay, utcDay(3, 6)},
+		// 00:30 in Lagos is still the previous day in UTC
+		{time.Date(2024, 3, 6, 0, 30, 0, 0, lagos), BucketDay, utcDay(3, 5)},
+		{time.Date(2024, 3, 6, 15, 0, 0, 0, time.UTC), BucketWeek, utcDay(3, 4)},
+		{utcDay(3, 4), BucketWeek, utcDay(3, 4)},
+		{time.Date(2024, 3, 10, 23, 0, 0, 0, time.UTC), BucketWeek, utcDay(3, 4)},
+		{time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), BucketWeek, utcDay(2, 26)},
+		{time.Date(2024, 3, 31, 23, 0, 0, 0, time.UTC), BucketMonth, utcDay(3, 1)},
+	}
+
+	for _, tt := range tests {
+		if got := truncateBucket(tt.t, tt.bucket); !got.Equal(tt.want) {
+			t.Errorf("truncateBucket(%s, %s) = %s, want %s", tt.t, tt.bucket, got, tt.want)
+		}
+	}
+}
+
+func TestBuildHistory(t *testing.T) {
+	buckets := map[time.Time][]float64{
+		utcDay(3, 1): {100, 300, 200},
+		utcDay(3, 2): {250},
+		// 3 March has no reports
+		utcDay(3, 4): {300},
+		utcDay(3, 5): {0},
+		utcDay(3, 6): {50},
+		utcDay(3, 7): {25},
+	}
+	points := BuildHistory(buckets, BucketDay, time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), time.Date(2024, 3, 7, 18, 0, 0, 0, time.UTC))
+
+	type want struct {
+		gap       bool
+		median    float64
+		count     int
+		changePct *float64
+	}
+	pct := func(v float64) *float64 { return &v }
+	wants := []want{
+		{median: 200, count: 3},
+		{median: 250, count: 1, changePct: pct(25)},
+		{gap: true},
+		// The bucket after a gap has nothing to compare with
+		{median: 300, count: 1},
+		{median: 0, count: 1, changePct: pct(-100)},
+		// A zero median is no base for a percentage
+		{median: 50, count: 1},
+		{median: 25, count: 1, changePct: pct(-50)},
+	}
+
+	if len(points) != len(wants) {
+		t.Fatalf("%d points, want %d", len(points), len(wants))
+	}
+	for i, w := range wants {
+		p := points[i]
+		if !p.BucketStart.Equal(utcDay(3, 1+i)) {
+			t.Errorf("point %d starts %s, want %s", i, p.BucketStart, utcDay(3, 1+i))
+		}
+		if p.Gap != w.gap || p.Count != w.count {
+			t.Errorf("point %d = gap %v count %d, want gap %v count %d", i, p.Gap, p.Count, w.gap, w.count)
+		}
+		if w.gap {
+			if p.Median != nil || p.ChangePct != nil {
+				t.Errorf("gap %d has median %v and change %v", i, p.Median, p.ChangePct)
+			}
+			continue
+		}
+		if p.Median == nil || *p.Median != w.median {
+			t.Errorf("point %d median = %v, want %v", i, p.Median, w.median)
+		}
+		switch {
+		case w.changePct == nil && p.ChangePct != nil:
+			t.Errorf("point %d change = %v, want none", i, *p.ChangePct)
+		case w.changePct != nil && (p.ChangePct == nil || *p.ChangePct != *w.changePct):
+			t.Errorf("point %d change = %v, want %v", i, p.ChangePct, *w.changePct)
+		}
+	}
+}
+
+func TestBuildHistoryRoundsChange(t *testing.T) {
+	buckets := map[time.Time][]float64{utcDay(3, 4): {300}, utcDay(3, 11): {400}}
+	points := BuildHistory(buckets, BucketWeek, utcDay(3, 6), utcDay(3, 11))
+	if len(points) != 2 || points[1].ChangePct == nil || *points[1].ChangePct != 33.33 {
+		t.Fatalf("points = %+v, want a 33.33%% rise in week two", points)
+	}
+}
+
+func TestBuildHistoryEmpty(t *testing.T) {
+	points := BuildHistory(nil, BucketMonth, utcDay(1, 15), utcDay(3, 2))
+	if len(points) != 3 {
+		t.Fatalf("%d points, want 3", len(points))
+	}
+	for i, p := range points {
+		if !p.Gap || !p.BucketStart.Equal(utcDay(time.Month(i+1), 1)) {
+			t.Errorf("point %d = %+v, want a gap on the 1st", i, p)
+		}
+	}
+}
+
+func TestBucketReports(t *testing.T) {
+	report := func(observed time.Time, kobo int64) model.PriceReport {
+		return model.PriceReport{ObservedAt: observed, Price: model.Money{Amount: kobo, Currency: "NGN"}}
+	}
+	buckets := bucketReports([]model.PriceReport{
+		report(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC), 15000),
+		report(time.Date(2024, 3, 10, 22, 0, 0, 0, time.UTC), 25050),
+		report(time.Date(2024, 3, 11, 1, 0, 0, 0, time.UTC), 10000),
+	}, BucketWeek)
+
+	if got := buckets[utcDay(3, 4)]; len(got) != 2 || got[0] != 150 || got[1] != 250.5 {
+		t.Errorf("week of 4 March = %v, want [150 250.5]", got)
+	}
+	if got := buckets[utcDay(3, 11)]; len(got) != 1 || got[0] != 100 {
+		t.Errorf("week of 11 March = %v, want [100]", got)
+	}
+}
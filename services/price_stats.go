@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	DefaultStatsWindow = "30d"
+	maxStatsWindow     = 366 * 24 * time.Hour
+)
+
+// MixedCurrencyError rejects an aggregate over reports in several currencies
+type MixedCurrencyError struct {
+	Currencies []string
+}
+
+func (e *MixedCurrencyError) Error() string {
+	return fmt.Sprintf("reports are in several currencies (%s); pass ?currency= to convert them",
+		strings.Join(e.Currencies, ", "))
+}
+
+// PriceStats summarises a set of prices in major currency units (naira, not
+// kobo). The median averages the two middle values of an even-sized set, as
+// everywhere else a median is shown; the quartiles use the nearest-rank
+// method. The Mongo pipeline and ComputePriceStats agree exactly.
+// WeightedMean weights each report by its reporter's reputation at
+// submission.
+type PriceStats struct {
+	Area         string  `json:"area,omitempty" bson:"_id"`
+	Count        int     `json:"count" bson:"count"`
+	Min          float64 `json:"min" bson:"min"`
+	Max          float64 `json:"max" bson:"max"`
+	Mean         float64 `json:"mean" bson:"mean"`
+	WeightedMean float64 `json:"weighted_mean" bson:"weighted_mean"`
+	Median       float64 `json:"median" bson:"median"`
+	P25          float64 `json:"p25" bson:"p25"`
+	P75          float64 `json:"p75" bson:"p75"`
+}
+
+type ItemPriceStats struct {
+	ItemID    string        `json:"item_id"`
+	Currency  string        `json:"currency"`
+	Window    string        `json:"window"`
+	Since     time.Time     `json:"since"`
+	Overall   PriceStats    `json:"overall"`
+	Areas     []PriceStats  `json:"areas"`
+	RatesUsed []AppliedRate `json:"rates_used,omitempty"`
+}
+
+// ParseWindow accepts durations such as "24h", "7d" or "4w"
+func ParseWindow(window string) (time.Duration, error) {
+	if window == "" {
+		window = DefaultStatsWindow
+	}
+	if len(window) < 2 {
+		return 0, errors.New("invalid window")
+	}
+
+	n, err := strconv.Atoi(window[:len(window)-1])
+	if err != nil || n <= 0 {
+		return 0, errors.New("invalid window")
+	}
+
+	var unit time.Duration
+	switch strings.ToLower(window[len(window)-1:]) {
+	case "h":
+		unit = time.Hour
+	case "d":
+		unit = 24 * time.Hour
+	case "w":
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, errors.New("invalid window, use h, d or w (e.g. 7d)")
+	}
+
+	d := time.Duration(n) * unit
+	if d > maxStatsWindow {
+		return 0, errors.New("window must not exceed one year")
+	}
+	return d, nil
+}
+
+// --- Pure Go computation ---
+
+// percentileRank is the zero-based nearest-rank index for p in [0,1]
+func percentileRank(p float64, n int) int {
+	idx := int(math.Ceil(p*float64(n))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return idx
+}
+
+// median of an ascending slice; the mean of the two middle values when the
+// length is even. Shared by the price stats and outlier scoring.
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// ComputePriceStats summarises prices in memory
+func ComputePriceStats(prices []float64) PriceStats {
+	stats := PriceStats{Count: len(prices)}
+	if len(prices) == 0 {
+		return stats
+	}
+
+	sorted := append([]float64(nil), prices...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, p := range sorted {
+		sum += p
+	}
+
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+	stats.Mean = sum / float64(len(sorted))
+	stats.WeightedMean = stats.Mean
+	stats.Median = median(sorted)
+	stats.P25 = sorted[percentileRank(0.25, len(sorted))]
+	stats.P75 = sorted[percentileRank(0.75, len(sorted))]
+	return stats
+}
+
+// ComputeWeightedMean is the reputation-weighted mean of a set of reports.
+// Reports without a positive weight count as neutral.
+func ComputeWeightedMean(reports []model.PriceReport) float64 {
+	sum, total := 0.0, 0.0
+	for _, r := range reports {
+		w := r.Weight
+		if w <= 0 {
+			w = neutralReputation
+		}
+		sum += r.Price.Major() * w
+		total += w
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+func summariseReports(reports []model.PriceReport) PriceStats {
+	prices := make([]float64, len(reports))
+	for i, r := range reports {
+		prices[i] = r.Price.Major()
+	}
+	stats := ComputePriceStats(prices)
+	stats.WeightedMean = ComputeWeightedMean(reports)
+	return stats
+}
+
+// ComputeItemPriceStats groups reports by area and summarises each group
+func ComputeItemPriceStats(reports []model.PriceReport) (PriceStats, []PriceStats) {
+	byArea := map[string][]model.PriceReport{}
+	for _, r := range reports {
+		byArea[r.Area] = append(byArea[r.Area], r)
+	}
+
+	areas := make([]PriceStats, 0, len(byArea))
+	for area, group := range byArea {
+		stats := summariseReports(group)
+		stats.Area = area
+		areas = append(areas, stats)
+	}
+	sortAreaStats(areas)
+
+	return summariseReports(reports), areas
+}
+
+func sortAreaStats(areas []PriceStats) {
+	sort.Slice(areas, func(i, j int) bool {
+		if areas[i].Count != areas[j].Count {
+			return areas[i].Count > areas[j].Count
+		}
+		return areas[i].Area < areas[j].Area
+	})
+}
+
+// --- Mongo aggregation ---
+
+// percentileExpr picks the nearest-rank element out of the sorted prices
+func percentileExpr(p float64) bson.M {
+	rank := bson.M{"$subtract": bson.A{bson.M{"$ceil": bson.M{"$multiply": bson.A{p, "$count"}}}, 1}}
+	return bson.M{"$arrayElemAt": bson.A{"$sorted", bson.M{"$toInt": bson.M{"$max": bson.A{rank, 0}}}}}
+}
+
+// medianExpr averages the two middle elements of the sorted prices, which
+// are the same element when the count is odd
+func medianExpr() bson.M {
+	middle := func(round string) bson.M {
+		index := bson.M{round: bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{"$count", 1}}, 2}}}
+		return bson.M{"$arrayElemAt": bson.A{"$sorted", bson.M{"$toInt": index}}}
+	}
+	return bson.M{"$avg": bson.A{middle("$floor"), middle("$ceil")}}
+}
+
+// valueStage adds "value", the report's price in major units. Reports must
+// already be narrowed to a single currency.
+func valueStage(currency string) bson.D {
+	exp, _ := model.CurrencyExponent(currency)
+	return bson.D{{Key: "$addFields", Value: bson.M{
+		"value": bson.M{"$divide": bson.A{"$price.amount", math.Pow10(exp)}},
+	}}}
+}
+
+func statsStages(groupKey interface{}) bson.A {
+	// Missing or zero weights count as neutral, as in ComputeWeightedMean
+	weight := bson.M{"$cond": bson.A{
+		bson.M{"$gt": bson.A{bson.M{"$ifNull": bson.A{"$weight", 0}}, 0}},
+		"$weight",
+		neutralReputation,
+	}}
+	return bson.A{
+		bson.M{"$group": bson.M{
+			"_id":          groupKey,
+			"count":        bson.M{"$sum": 1},
+			"min":          bson.M{"$min": "$value"},
+			"max":          bson.M{"$max": "$value"},
+			"mean":         bson.M{"$avg": "$value"},
+			"weighted_sum": bson.M{"$sum": bson.M{"$multiply": bson.A{"$value", weight}}},
+			"weight_total": bson.M{"$sum": weight},
+			"prices":       bson.M{"$push": "$value"},
+		}},
+		bson.M{"$addFields": bson.M{"sorted": bson.M{"$sortArray": bson.M{"input": "$prices", "sortBy": 1}}}},
+		bson.M{"$project": bson.M{
+			"count": 1,
+			"min":   1,
+			"max":   1,
+			"mean":  1,
+			"weighted_mean": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{"$weight_total", 0}},
+				bson.M{"$divide": bson.A{"$weighted_sum", "$weight_total"}},
+				"$mean",
+			}},
+			"median": medianExpr(),
+			"p25":    percentileExpr(0.25),
+			"p75":    percentileExpr(0.75),
+		}},
+	}
+}
+
+// statsMatch selects the reports that feed an item's aggregates. Suspect
+// reports stay out until reviewed.
+func statsMatch(itemID primitive.ObjectID, area string, since time.Time) bson.M {
+	match := bson.M{
+		"item_id":     itemID,
+		"observed_at": bson.M{"$gte": since},
+		"status":      bson.M{"$nin": excludedReportStatuses},
+	}
+	if area != "" {
+		match["area"] = area
+	}
+	return match
+}
+
+func aggregatePriceStats(ctx context.Context, match bson.M, currency string) (PriceStats, []PriceStats, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		valueStage(currency),
+		{{Key: "$facet", Value: bson.M{
+			"overall": statsStages(nil),
+			"areas":   statsStages("$area"),
+		}}},
+	}
+
+	cursor, err := priceReportCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return PriceStats{}, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Overall []PriceStats `bson:"overall"`
+		Areas   []PriceStats `bson:"areas"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return PriceStats{}, nil, err
+	}
+
+	overall := PriceStats{}
+	areas := []PriceStats{}
+	if len(result) > 0 {
+		if len(result[0].Overall) > 0 {
+			overall = result[0].Overall[0]
+			overall.Area = ""
+		}
+		areas = append(areas, result[0].Areas...)
+	}
+	sortAreaStats(areas)
+	return overall, areas, nil
+}
+
+func findPriceReports(ctx context.Context, match bson.M) ([]model.PriceReport, error) {
+	cursor, err := priceReportCollection.Find(ctx, match)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var reports []model.PriceReport
+	err = cursor.All(ctx, &reports)
+	return reports, err
+}
+
+// resolveCurrency picks the single currency an aggregate is computed in:
+// the requested one, or the only currency the matching reports use. convert
+// is set when some reports must first be converted into it. Reports in
+// several currencies are never averaged together unconverted.
+func resolveCurrency(ctx context.Context, match bson.M, requested string) (currency string, convert bool, err error) {
+	values, err := priceReportCollection.Distinct(ctx, "price.currency", match)
+	if err != nil {
+		return "", false, err
+	}
+	currencies := make([]string, 0, len(values))
+	for _, v := range values {
+		if code, ok := v.(string); ok {
+			currencies = append(currencies, code)
+		}
+	}
+
+	if requested != "" {
+		currency, err := model.NormalizeCurrency(requested)
+		if err != nil {
+			return "", false, &FilterError{Err: err}
+		}
+		convert := len(currencies) > 1 || (len(currencies) == 1 && currencies[0] != currency)
+		return currency, convert, nil
+	}
+
+	switch len(currencies) {
+	case 0:
+		return config.DefaultCurrency, false, nil
+	case 1:
+		return currencies[0], false, nil
+	}
+	sort.Strings(currencies)
+	return "", false, &FilterError{Err: &MixedCurrencyError{Currencies: currencies}}
+}
+
+// convertedReports loads the matching reports with every price converted
+// into currency at the rate in effect on the day it was observed
+func convertedReports(ctx context.Context, match bson.M, currency string) ([]model.PriceReport, []AppliedRate, error) {
+	reports, err := findPriceReports(ctx, match)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load price reports: %w", err)
+	}
+	cv, err := NewCurrencyConverter(ctx, currency)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cv.convertReports(reports); err != nil {
+		return nil, nil, &FilterError{Err: err}
+	}
+	return reports, cv.RatesUsed(), nil
+}
+
+// 📊 Price statistics for an item over a window, broken down by area.
+// Servers without $sortArray (MongoDB < 5.2) fall back to the Go computation,
+// as do requests that need reports converted into another currency.
+func GetItemPriceStats(itemID, area, window, currency string) (*ItemPriceStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	objID, err := toObjectID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if window == "" {
+		window = DefaultStatsWindow
+	}
+	d, err := ParseWindow(window)
+	if err != nil {
+		return nil, &FilterError{Err: err}
+	}
+
+	since := time.Now().Add(-d)
+	match := statsMatch(objID, area, since)
+	currency, convert, err := resolveCurrency(ctx, match, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ItemPriceStats{ItemID: itemID, Currency: currency, Window: window, Since: since}
+	if convert {
+		reports, rates, err := convertedReports(ctx, match, currency)
+		if err != nil {
+			return nil, err
+		}
+		stats.Overall, stats.Areas = ComputeItemPriceStats(reports)
+		stats.RatesUsed = rates
+		return stats, nil
+	}
+
+	match["price.currency"] = currency
+	stats.Overall, stats.Areas, err = aggregatePriceStats(ctx, match, currency)
+	if err != nil {
+		log.Printf("⚠️ Stats aggregation failed, computing in Go: %v", err)
+		reports, findErr := findPriceReports(ctx, match)
+		if findErr != nil {
+			return nil, fmt.Errorf("failed to load price reports: %w", findErr)
+		}
+		stats.Overall, stats.Areas = ComputeItemPriceStats(reports)
+	}
+	return stats, nil
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type statsFixtureReport struct {
+	area   string
+	naira  float64
+	weight float64
+}
+
+// priceStatsFixtures are shared by the Go computation and the Mongo
+// pipeline; both must produce exactly these results
+var priceStatsFixtures = []struct {
+	name    string
+	reports []statsFixtureReport
+	overall PriceStats
+	areas   []PriceStats
+}{
+	{
+		name:    "no reports",
+		overall: PriceStats{},
+		areas:   []PriceStats{},
+	},
+	{
+		name:    "single report",
+		reports: []statsFixtureReport{{"Yaba", 500, 1}},
+		overall: PriceStats{Count: 1, Min: 500, Max: 500, Mean: 500, WeightedMean: 500, Median: 500, P25: 500, P75: 500},
+		areas:   []PriceStats{{Area: "Yaba", Count: 1, Min: 500, Max: 500, Mean: 500, WeightedMean: 500, Median: 500, P25: 500, P75: 500}},
+	},
+	{
+		name:    "odd count",
+		reports: []statsFixtureReport{{"Yaba", 300, 1}, {"Yaba", 100, 1}, {"Yaba", 200, 1}},
+		overall: PriceStats{Count: 3, Min: 100, Max: 300, Mean: 200, WeightedMean: 200, Median: 200, P25: 100, P75: 300},
+		areas:   []PriceStats{{Area: "Yaba", Count: 3, Min: 100, Max: 300, Mean: 200, WeightedMean: 200, Median: 200, P25: 100, P75: 300}},
+	},
+	{
+		// The median averages the middle pair; the quartiles are nearest-rank
+		name:    "even count",
+		reports: []statsFixtureReport{{"Yaba", 400, 1}, {"Yaba", 100, 1}, {"Yaba", 300, 1}, {"Yaba", 200, 1}},
+		overall: PriceStats{Count: 4, Min: 100, Max: 400, Mean: 250, WeightedMean: 250, Median: 250, P25: 100, P75: 300},
+		areas:   []PriceStats{{Area: "Yaba", Count: 4, Min: 100, Max: 400, Mean: 250, WeightedMean: 250, Median: 250, P25: 100, P75: 300}},
+	},
+	{
+		// A zero weight counts as neutral (0.5), the same as 0.5 given explicitly
+		name:    "areas and weights",
+		reports: []statsFixtureReport{{"Yaba", 100, 1}, {"Yaba", 200, 1}, {"Ikeja", 400, 0.5}, {"Ikeja", 700, 0}},
+		overall: PriceStats{Count: 4, Min: 100, Max: 700, Mean: 350, WeightedMean: 850.0 / 3, Median: 300, P25: 100, P75: 400},
+		areas: []PriceStats{
+			{Area: "Ikeja", Count: 2, Min: 400, Max: 700, Mean: 550, WeightedMean: 550, Median: 550, P25: 400, P75: 700},
+			{Area: "Yaba", Count: 2, Min: 100, Max: 200, Mean: 150, WeightedMean: 150, Median: 150, P25: 100, P75: 200},
+		},
+	},
+}
+
+func fixtureReports(itemID primitive.ObjectID, fixture []statsFixtureReport) []model.PriceReport {
+	reports := make([]model.PriceReport, len(fixture))
+	for i, r := range fixture {
+		reports[i] = model.PriceReport{
+			ID:         primitive.NewObjectID(),
+			ItemID:     itemID,
+			Area:       r.area,
+			Price:      model.Money{Amount: int64(r.naira * 100), Currency: "NGN"},
+			Weight:     r.weight,
+			Status:     model.ReportStatusPublished,
+			ObservedAt: time.Now().Add(-time.Hour),
+		}
+	}
+	return reports
+}
+
+func assertPriceStats(t *testing.T, label string, got, want PriceStats) {
+	t.Helper()
+	close := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	if got.Area != want.Area || got.Count != want.Count || !close(got.Min, want.Min) || !close(got.Max, want.Max) ||
+		!close(got.Mean, want.Mean) || !close(got.WeightedMean, want.WeightedMean) || !close(got.Median, want.Median) ||
+		!close(got.P25, want.P25) || !close(got.P75, want.P75) {
+		t.Errorf("%s = %+v, want %+v", label, got, want)
+	}
+}
+
+func assertItemPriceStats(t *testing.T, overall PriceStats, areas []PriceStats, wantOverall PriceStats, wantAreas []PriceStats) {
+	t.Helper()
+	assertPriceStats(t, "overall", overall, wantOverall)
+	if len(areas) != len(wantAreas) {
+		t.Fatalf("areas = %+v, want %+v", areas, wantAreas)
+	}
+	for i := range areas {
+		assertPriceStats(t, "area "+wantAreas[i].Area, areas[i], wantAreas[i])
+	}
+}
+
+func TestComputeItemPriceStats(t *testing.T) {
+	for _, tt := range priceStatsFixtures {
+		t.Run(tt.name, func(t *testing.T) {
+			overall, areas := ComputeItemPriceStats(fixtureReports(primitive.NewObjectID(), tt.reports))
+			assertItemPriceStats(t, overall, areas, tt.overall, tt.areas)
+		})
+	}
+}
+
+// testDatabase connects to the MongoDB server in TEST_MONGO_URI and points
+// every collection of the package at a throwaway database, dropped when the
+// test ends. Tests skip without a server.
+func testDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+	uri := os.Getenv("TEST_MONGO_URI")
+	if uri == "" {
+		t.Skip("TEST_MONGO_URI not set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := client.Database("crowd_market_test_" + primitive.NewObjectID().Hex())
+	collections := map[**mongo.Collection]string{
+		&productCollection:          "products",
+		&priceReportCollection:      "price_reports",
+		&itemCollection:             "items",
+		&userCollection:             "users",
+		&voteCollection:             "report_votes",
+		&revisionCollection:         "product_revisions",
+		&flagCollection:             "moderation_flags",
+		&moderationActionCollection: "moderation_actions",
+		&uploadCollection:           "uploads",
+		&unitCollection:             "units",
+		&exchangeRateCollection:     "exchange_rates",
+		&marketCollection:           "markets",
+		&categoryCollection:         "categories",
+		&locationCollection:         "locations",
+	}
+	saved := map[**mongo.Collection]*mongo.Collection{}
+	for collection, name := range collections {
+		saved[collection] = *collection
+		*collection = db.Collection(name)
+	}
+	t.Cleanup(func() {
+		for collection, previous := range saved {
+			*collection = previous
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = db.Drop(ctx)
+		_ = client.Disconnect(ctx)
+	})
+	return db
+}
+
+// TestAggregatePriceStats runs the same fixtures through the Mongo pipeline.
+// It needs a MongoDB 5.2+ server in TEST_MONGO_URI.
+func TestAggregatePriceStats(t *testing.T) {
+	testDatabase(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for _, tt := range priceStatsFixtures {
+		t.Run(tt.name, func(t *testing.T) {
+			itemID := primitive.NewObjectID()
+			for _, report := range fixtureReports(itemID, tt.reports) {
+				if _, err := priceReportCollection.InsertOne(ctx, report); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			match := statsMatch(itemID, "", time.Now().Add(-24*time.Hour))
+			match["price.currency"] = "NGN"
+			overall, areas, err := aggregatePriceStats(ctx, match, "NGN")
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertItemPriceStats(t, overall, areas, tt.overall, tt.areas)
+
+			// Both computations agree on the raw documents as well
+			reports, err := findPriceReports(ctx, bson.M{"item_id": itemID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			goOverall, goAreas := ComputeItemPriceStats(reports)
+			assertItemPriceStats(t, goOverall, goAreas, overall, areas)
+		})
+	}
+}
+
+func TestMedianIsSharedWithOutlierScoring(t *testing.T) {
+	sample := []float64{100, 200, 300, 400}
+	_, med, ok := RobustScore(sample, 250)
+	if !ok {
+		t.Fatal("RobustScore rejected a non-empty sample")
+	}
+	if stats := ComputePriceStats(sample); stats.Median != med {
+		t.Errorf("stats median %g differs from outlier median %g", stats.Median, med)
+	}
+}
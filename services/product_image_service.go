@@ -0,0 +1,395 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"sort"
+	"sync"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrTooManyImages    = errors.New("too many images")
+	ErrImageNotFound    = errors.New("image not found")
+	ErrImagesChanged    = errors.New("the product's images changed meanwhile, please retry")
+	ErrLastProductImage = errors.New("a product needs at least one image")
+)
+
+// maxConcurrentUploads bounds the uploads one request runs in parallel
+const maxConcurrentUploads = 4
+
+// 📤 Upload several images at once, returning them in input order. If any
+// upload fails, the ones that succeeded are deleted again.
+func UploadImages(headers []*multipart.FileHeader) ([]StoredImage, error) {
+	stored := make([]StoredImage, len(headers))
+	errs := make([]error, len(headers))
+	slots := make(chan struct{}, maxConcurrentUploads)
+
+	var wg sync.WaitGroup
+	for i, header := range headers {
+		wg.Add(1)
+		go func(i int, header *multipart.FileHeader) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			file, err := header.Open()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer file.Close()
+			image, err := UploadImage(file)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", header.Filename, err)
+				return
+			}
+			stored[i] = *image
+		}(i, header)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		DeleteUnusedImages(storedURLs(stored))
+		return nil, err
+	}
+	return stored, nil
+}
+
+// 🗑️ DeleteUnusedImages removes images no product references any more,
+// logging the ones that fail; the image collector retries those. Uploads
+// are named by content hash, so the same file can back several products.
+func DeleteUnusedImages(urls []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, url := range urls {
+		if url == "" {
+			continue
+		}
+		inUse, err := productCollection.CountDocuments(ctx, bson.M{"$or": bson.A{
+			bson.M{"image_url": url},
+			bson.M{"images.url": url},
+		}})
+		if err != nil {
+			log.Printf("⚠️ Failed to check references to image %s: %v", url, err)
+			continue
+		}
+		if inUse > 0 {
+			continue
+		}
+		if err := DeleteImage(url); err != nil {
+			log.Printf("⚠️ Failed to delete image %s: %v", url, err)
+		}
+	}
+}
+
+func storedURLs(stored []StoredImage) []string {
+	urls := make([]string, 0, len(stored))
+	for _, image := range stored {
+		urls = append(urls, image.URL)
+	}
+	return urls
+}
+
+// NewProductImages wraps freshly uploaded images for a new product; the
+// first image is primary
+func NewProductImages(stored []StoredImage) []model.ProductImage {
+	now := time.Now()
+	images := make([]model.ProductImage, len(stored))
+	for i, image := range stored {
+		images[i] = model.ProductImage{
+			ID:        primitive.NewObjectID(),
+			URL:       image.URL,
+			Thumbnail: image.ThumbnailURL,
+			Medium:    image.MediumURL,
+			Position:  i,
+			Primary:   i == 0,
+			CreatedAt: now,
+		}
+	}
+	return images
+}
+
+// ProductImageURLs lists every image a product references, including a
+// legacy ImageURL that predates multiple images
+func ProductImageURLs(p model.Product) []string {
+	seen := map[string]bool{}
+	var urls []string
+	add := func(url string) {
+		if url != "" && !seen[url] {
+			seen[url] = true
+			urls = append(urls, url)
+		}
+	}
+	for _, image := range p.Images {
+		add(image.URL)
+	}
+	add(p.ImageURL)
+	return urls
+}
+
+// productImages returns a product's images in order, turning a legacy
+// ImageURL into a single primary image. That image borrows the product's
+// own ID so it can be addressed across requests before it is ever stored
+func productImages(p model.Product) []model.ProductImage {
+	if len(p.Images) == 0 && p.ImageURL != "" {
+		created := p.ID.Timestamp()
+		if p.CreatedAt != nil {
+			created = *p.CreatedAt
+		}
+		return []model.ProductImage{{ID: p.ID, URL: p.ImageURL, Primary: true, CreatedAt: created}}
+	}
+	images := append([]model.ProductImage{}, p.Images...)
+	sort.SliceStable(images, func(i, j int) bool { return images[i].Position < images[j].Position })
+	return images
+}
+
+// withPrimaryImage is the product's image list with the primary image
+// replaced by stored
+func withPrimaryImage(p model.Product, stored StoredImage) []model.ProductImage {
+	replacement := NewProductImages([]StoredImage{stored})[0]
+	images := productImages(p)
+	for i := range images {
+		if images[i].Primary {
+			replacement.Position = images[i].Position
+			images[i] = replacement
+			return images
+		}
+	}
+	images = append([]model.ProductImage{replacement}, images...)
+	normalizeImages(images)
+	return images
+}
+
+// normalizeImages renumbers positions and makes sure exactly one image is
+// primary, falling back to the first
+func normalizeImages(images []model.ProductImage) {
+	primary := -1
+	for i := range images {
+		images[i].Position = i
+		if images[i].Primary {
+			if primary >= 0 {
+				images[i].Primary = false
+			} else {
+				primary = i
+			}
+		}
+	}
+	if primary < 0 && len(images) > 0 {
+		images[0].Primary = true
+	}
+}
+
+func primaryImageURL(images []model.ProductImage) string {
+	for _, image := range images {
+		if image.Primary {
+			return image.URL
+		}
+	}
+	return ""
+}
+
+// saveProductImages writes a new image list for an owned product, matching
+// on the list it was derived from so concurrent edits are not lost. The
+// change is recorded in the product's history.
+func saveProductImages(ctx context.Context, product model.Product, images []model.ProductImage) error {
+	filter := bson.M{"_id": product.ID, "user_id": product.UserID, "deleted_at": nil}
+	if len(product.Images) == 0 {
+		filter["images"] = bson.M{"$in": bson.A{nil, bson.A{}}}
+	} else {
+		filter["images"] = product.Images
+	}
+
+	result, err := productCollection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{
+		"images":     images,
+		"image_url":  primaryImageURL(images),
+		"updated_at": time.Now(),
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrImagesChanged
+	}
+
+	if changes := imageChanges(product, images); len(changes) > 0 {
+		recordRevision(ctx, product.ID, product.UserID, model.RevisionUpdate, changes)
+	}
+	return nil
+}
+
+// imageChanges diffs a new image list, and the primary image URL it
+// implies, against the product it replaces
+func imageChanges(product model.Product, images []model.ProductImage) []model.FieldChange {
+	before, err := bson.Marshal(product)
+	if err != nil {
+		log.Printf("⚠️ Failed to encode product %s for its history: %v", product.ID.Hex(), err)
+		return nil
+	}
+	return diffFields(before, map[string]interface{}{
+		"images":    images,
+		"image_url": primaryImageURL(images),
+	})
+}
+
+// ownedProduct loads a live product owned by userID
+func ownedProduct(ctx context.Context, productID, userID string) (model.Product, error) {
+	filter, err := buildFilter(productID, userID)
+	if err != nil {
+		return model.Product{}, err
+	}
+	var product model.Product
+	err = productCollection.FindOne(ctx, filter).Decode(&product)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.Product{}, notOwnedError(ctx, filter["_id"].(primitive.ObjectID))
+	}
+	return product, err
+}
+
+// 🖼️ Make an already stored image the product's primary image. Returns the
+// updated product and the URL of the image it replaced, which the caller
+// deletes once nothing references it.
+func ReplacePrimaryImage(productID, userID string, stored StoredImage) (*model.Product, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	product, err := ownedProduct(ctx, productID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	replaced := primaryImageURL(productImages(product))
+	images := withPrimaryImage(product, stored)
+	if err := saveProductImages(ctx, product, images); err != nil {
+		return nil, "", err
+	}
+	product.Images = images
+	product.ImageURL = primaryImageURL(images)
+	return &product, replaced, nil
+}
+
+// 🖼️ Upload more images for a product, from form files and direct uploads,
+// appended after the existing ones
+func AddProductImages(productID, userID string, headers []*multipart.FileHeader, uploadIDs []string) ([]model.ProductImage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	product, err := ownedProduct(ctx, productID, userID)
+	if err != nil {
+		return nil, err
+	}
+	images := productImages(product)
+	if len(images)+len(headers)+len(uploadIDs) > config.MaxProductImages {
+		return nil, fmt.Errorf("%w: a product can have at most %d", ErrTooManyImages, config.MaxProductImages)
+	}
+
+	stored, err := CollectImages(product.UserID, headers, uploadIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, image := range NewProductImages(stored) {
+		image.Primary = false
+		images = append(images, image)
+	}
+	normalizeImages(images)
+
+	if err := saveProductImages(ctx, product, images); err != nil {
+		DeleteUnusedImages(storedURLs(stored))
+		return nil, err
+	}
+	return images, nil
+}
+
+// 🗑️ Remove one image from a product and from the image store. Removing the
+// primary image promotes the next one.
+func DeleteProductImage(productID, userID, imageID string) ([]model.ProductImage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	product, err := ownedProduct(ctx, productID, userID)
+	if err != nil {
+		return nil, err
+	}
+	images := productImages(product)
+	removed := -1
+	for i, image := range images {
+		if image.ID.Hex() == imageID {
+			removed = i
+		}
+	}
+	if removed < 0 {
+		return nil, ErrImageNotFound
+	}
+	if len(images) == 1 {
+		return nil, ErrLastProductImage
+	}
+
+	url := images[removed].URL
+	images = append(images[:removed], images[removed+1:]...)
+	normalizeImages(images)
+	if err := saveProductImages(ctx, product, images); err != nil {
+		return nil, err
+	}
+	DeleteUnusedImages([]string{url})
+	return images, nil
+}
+
+// 🔀 Put a product's images in the given order. order must list every image
+// ID exactly once; primaryID, when set, also moves the primary flag.
+func ReorderProductImages(productID, userID string, order []string, primaryID string) ([]model.ProductImage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	product, err := ownedProduct(ctx, productID, userID)
+	if err != nil {
+		return nil, err
+	}
+	images := productImages(product)
+	if order == nil {
+		for _, image := range images {
+			order = append(order, image.ID.Hex())
+		}
+	}
+	if len(order) != len(images) {
+		return nil, &FilterError{Err: errors.New("order must list every image exactly once")}
+	}
+
+	byID := make(map[string]model.ProductImage, len(images))
+	for _, image := range images {
+		byID[image.ID.Hex()] = image
+	}
+	if primaryID != "" {
+		if _, ok := byID[primaryID]; !ok {
+			return nil, ErrImageNotFound
+		}
+	}
+
+	reordered := make([]model.ProductImage, 0, len(order))
+	for _, id := range order {
+		image, ok := byID[id]
+		if !ok {
+			return nil, &FilterError{Err: errors.New("order must list every image exactly once")}
+		}
+		delete(byID, id)
+		if primaryID != "" {
+			image.Primary = id == primaryID
+		}
+		reordered = append(reordered, image)
+	}
+	normalizeImages(reordered)
+
+	if err := saveProductImages(ctx, product, reordered); err != nil {
+		return nil, err
+	}
+	return reordered, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWithPrimaryImage(t *testing.T) {
+	created := time.Now()
+	stored := StoredImage{URL: "https://img.example/new.jpg"}
+
+	tests := []struct {
+		name    string
+		product model.Product
+		want    []string
+	}{
+		{"no images", model.Product{}, []string{"https://img.example/new.jpg"}},
+		{
+			"legacy image_url",
+			model.Product{ImageURL: "https://img.example/old.jpg", CreatedAt: &created},
+			[]string{"https://img.example/new.jpg"},
+		},
+		{
+			"primary replaced in place",
+			model.Product{Images: []model.ProductImage{
+				{URL: "https://img.example/a.jpg", Position: 0},
+				{URL: "https://img.example/b.jpg", Position: 1, Primary: true},
+				{URL: "https://img.example/c.jpg", Position: 2},
+			}},
+			[]string{"https://img.example/a.jpg", "https://img.example/new.jpg", "https://img.example/c.jpg"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			images := withPrimaryImage(tt.product, stored)
+			if len(images) != len(tt.want) {
+				t.Fatalf("images = %+v, want %v", images, tt.want)
+			}
+			for i, image := range images {
+				if image.URL != tt.want[i] || image.Position != i {
+					t.Errorf("image %d = %s at %d, want %s at %d", i, image.URL, image.Position, tt.want[i], i)
+				}
+			}
+			if got := primaryImageURL(images); got != stored.URL {
+				t.Errorf("primary = %q, want %q", got, stored.URL)
+			}
+		})
+	}
+}
+
+func TestProductImagesLegacyIDIsStable(t *testing.T) {
+	product := model.Product{ID: primitive.NewObjectID(), ImageURL: "https://img.example/old.jpg"}
+
+	first, second := productImages(product), productImages(product)
+	if len(first) != 1 || len(second) != 1 {
+		t.Fatalf("images = %+v and %+v, want one each", first, second)
+	}
+	if first[0].ID != second[0].ID || first[0].ID.IsZero() {
+		t.Errorf("legacy image IDs %s and %s differ", first[0].ID.Hex(), second[0].ID.Hex())
+	}
+	if !first[0].CreatedAt.Equal(second[0].CreatedAt) {
+		t.Errorf("legacy image created at %v then %v", first[0].CreatedAt, second[0].CreatedAt)
+	}
+	if !first[0].Primary || first[0].URL != product.ImageURL {
+		t.Errorf("legacy image = %+v", first[0])
+	}
+}
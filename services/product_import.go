@@ -0,0 +1,466 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Import formats
+const (
+	ImportCSV    = "csv"
+	ImportNDJSON = "ndjson"
+)
+
+// importBatchSize is how many rows go into one InsertMany
+const importBatchSize = 100
+
+// importFields are the product fields an import can fill. Each reads the
+// column of the same name unless the mapping names another.
+var importFields = []string{
+	"name", "price", "currency", "quantity", "unit", "area", "location_id",
+	"market_id", "category", "description", "lat", "lng", "date",
+}
+
+// ImportOptions describe how to read an import
+type ImportOptions struct {
+	Format string
+	// Mapping maps product fields to source columns, e.g. {"price": "Price (₦)"}
+	Mapping map[string]string
+	// Currency applies to prices that name none; DEFAULT_CURRENCY otherwise
+	Currency string
+	DryRun   bool
+}
+
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+type ImportReport struct {
+	DryRun   bool `json:"dry_run"`
+	Rows     int  `json:"rows"`
+	Valid    int  `json:"valid"`
+	Inserted int  `json:"inserted"`
+	// HeldForReview counts inserted rows that are pending or suspect
+	HeldForReview int              `json:"held_for_review"`
+	Errors        []ImportRowError `json:"errors"`
+}
+
+// importRow is one source record by lower-cased column name, with the line
+// it started on
+type importRow struct {
+	line   int
+	values map[string]string
+}
+
+// importedProduct is a validated row waiting to be inserted
+type importedProduct struct {
+	line       int
+	product    model.Product
+	assessment PriceAssessment
+}
+
+// 📥 Import price reports from CSV (with a header row) or NDJSON. Every row
+// is validated and every problem reported with its line; valid rows are
+// inserted in batches unless opts.DryRun is set.
+func ImportProducts(r io.Reader, userID primitive.ObjectID, opts ImportOptions) (*ImportReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	columns, err := importColumns(opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Currency == "" {
+		opts.Currency = config.DefaultCurrency
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun, Errors: []ImportRowError{}}
+	var rows []importRow
+	switch opts.Format {
+	case ImportCSV:
+		rows, err = readCSVRows(r, columns, report)
+	case ImportNDJSON:
+		rows, err = readNDJSONRows(r, report)
+	default:
+		return nil, &FilterError{Err: fmt.Errorf("unsupported import format %q, use csv or ndjson", opts.Format)}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var valid []importedProduct
+	for _, row := range rows {
+		product, problems := productFromRow(ctx, row, columns, userID, opts.Currency)
+		if len(problems) > 0 {
+			report.Errors = append(report.Errors, problems...)
+			continue
+		}
+		valid = append(valid, importedProduct{line: row.line, product: product})
+	}
+	report.Valid = len(valid)
+	if opts.DryRun || len(valid) == 0 {
+		sortImportErrors(report.Errors)
+		return report, nil
+	}
+
+	reputation := userReputation(ctx, userID)
+	for start := 0; start < len(valid); start += importBatchSize {
+		batch := valid[start:min(start+importBatchSize, len(valid))]
+		if err := insertImportBatch(ctx, batch, reputation, report); err != nil {
+			sortImportErrors(report.Errors)
+			return report, err
+		}
+	}
+	sortImportErrors(report.Errors)
+	return report, nil
+}
+
+// importColumns resolves the source column of every importable field
+func importColumns(mapping map[string]string) (map[string]string, error) {
+	known := map[string]bool{}
+	columns := map[string]string{}
+	for _, field := range importFields {
+		known[field] = true
+		columns[field] = field
+	}
+	for field, column := range mapping {
+		if !known[field] {
+			return nil, &FilterError{Err: fmt.Errorf("cannot map unknown field %q", field)}
+		}
+		columns[field] = strings.ToLower(strings.TrimSpace(column))
+	}
+	return columns, nil
+}
+
+func readCSVRows(r io.Reader, columns map[string]string, report *ImportReport) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, &FilterError{Err: errors.New("CSV is empty or unreadable")}
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	present := map[string]bool{}
+	for _, name := range header {
+		present[name] = true
+	}
+	for _, required := range []string{"name", "price"} {
+		if !present[columns[required]] {
+			return nil, &FilterError{Err: fmt.Errorf("CSV header must include a column for %q", required)}
+		}
+	}
+	if !present[columns["area"]] && !present[columns["location_id"]] && !present[columns["market_id"]] {
+		return nil, &FilterError{Err: errors.New("CSV header must include a column for area, location_id or market_id")}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		report.Rows++
+		if err := checkImportSize(report.Rows); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			report.Errors = append(report.Errors, ImportRowError{Row: parseErr.Line, Error: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		values := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(record) {
+				values[name] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, importRow{line: line, values: values})
+	}
+}
+
+func readNDJSONRows(r io.Reader, report *ImportReport) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	var rows []importRow
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		report.Rows++
+		if err := checkImportSize(report.Rows); err != nil {
+			return nil, err
+		}
+
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &object); err != nil {
+			report.Errors = append(report.Errors, ImportRowError{Row: line, Error: "invalid JSON: " + err.Error()})
+			continue
+		}
+		values := make(map[string]string, len(object))
+		for key, value := range object {
+			switch v := value.(type) {
+			case nil:
+				// A null is the same as a missing column
+			case string:
+				values[strings.ToLower(key)] = strings.TrimSpace(v)
+			case float64:
+				values[strings.ToLower(key)] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				values[strings.ToLower(key)] = fmt.Sprint(v)
+			}
+		}
+		rows = append(rows, importRow{line: line, values: values})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &FilterError{Err: fmt.Errorf("reading NDJSON: %w", err)}
+	}
+	return rows, nil
+}
+
+func checkImportSize(rows int) error {
+	if rows > config.MaxImportRows {
+		return &FilterError{Err: fmt.Errorf("an import can have at most %d rows", config.MaxImportRows)}
+	}
+	return nil
+}
+
+// productFromRow validates one row and builds its product, or returns every
+// problem found with it
+func productFromRow(ctx context.Context, row importRow, columns map[string]string, userID primitive.ObjectID, currency string) (model.Product, []ImportRowError) {
+	var problems []ImportRowError
+	fail := func(field string, err error) {
+		problems = append(problems, ImportRowError{Row: row.line, Field: field, Error: err.Error()})
+	}
+	field := func(name string) string { return row.values[columns[name]] }
+
+	product := model.Product{
+		UserID:      userID,
+		Name:        field("name"),
+		Area:        field("area"),
+		Category:    field("category"),
+		Description: field("description"),
+	}
+	if product.Name == "" {
+		fail("name", errors.New("name is required"))
+	}
+
+	if raw := field("price"); raw == "" {
+		fail("price", errors.New("price is required"))
+	} else {
+		rowCurrency := field("currency")
+		if rowCurrency == "" {
+			rowCurrency = currency
+		}
+		price, err := model.ParseMoney(raw, rowCurrency)
+		if err != nil {
+			fail("price", err)
+		}
+		product.Price = price
+	}
+
+	if quantity, unit := field("quantity"), field("unit"); quantity != "" || unit != "" {
+		if unit == "" {
+			fail("unit", errors.New("unit is required with quantity"))
+		} else if u, err := LookupUnit(unit); err != nil {
+			fail("unit", err)
+		} else {
+			product.Unit, product.Quantity = u.Code, 1
+			if quantity != "" {
+				q, err := ParseQuantity(quantity)
+				if err != nil {
+					fail("quantity", err)
+				}
+				product.Quantity = q
+			}
+		}
+	}
+
+	if !IsKnownCategory(product.Category) {
+		fail("category", fmt.Errorf("%w: %q", ErrUnknownCategory, product.Category))
+	}
+
+	if lat, lng := field("lat"), field("lng"); lat != "" || lng != "" {
+		latValue, latErr := parseFiniteFloat(lat)
+		lngValue, lngErr := parseFiniteFloat(lng)
+		switch {
+		case lat == "" || lng == "":
+			fail("lat", errors.New("lat and lng must be provided together"))
+		case latErr != nil || lngErr != nil:
+			fail("lat", errors.New("invalid lat or lng value"))
+		default:
+			if err := ValidateCoordinates(latValue, lngValue); err != nil {
+				fail("lat", err)
+			} else {
+				product.Location = model.NewGeoPoint(latValue, lngValue)
+			}
+		}
+	}
+
+	observed := time.Now()
+	if raw := field("date"); raw != "" {
+		date, err := parseImportDate(raw)
+		if err != nil {
+			fail("date", err)
+		} else if date.After(observed) {
+			fail("date", errors.New("date is in the future"))
+		} else {
+			observed = date
+		}
+	}
+	product.CreatedAt = &observed
+
+	if raw := field("market_id"); raw != "" {
+		if id, err := primitive.ObjectIDFromHex(raw); err != nil {
+			fail("market_id", ErrMarketNotFound)
+		} else {
+			product.MarketID = &id
+		}
+	}
+	if raw := field("location_id"); raw != "" {
+		if id, err := primitive.ObjectIDFromHex(raw); err != nil {
+			fail("location_id", ErrLocationNotFound)
+		} else {
+			product.LocationID = &id
+		}
+	}
+	if len(problems) > 0 {
+		return product, problems
+	}
+
+	if err := applyProductMarket(&product); err != nil {
+		fail("market_id", err)
+		return product, problems
+	}
+	if err := applyProductLocation(ctx, &product); err != nil {
+		fail("location_id", err)
+		return product, problems
+	}
+	if strings.TrimSpace(product.Area) == "" {
+		fail("area", errors.New("area, location_id or market_id is required"))
+	}
+	return product, problems
+}
+
+// Dates are RFC3339 timestamps or plain dates (YYYY-MM-DD, UTC midnight)
+func parseImportDate(raw string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("date must be YYYY-MM-DD or RFC3339")
+}
+
+// insertImportBatch saves one batch of validated rows. Rows the database
+// rejects are reported; the rest get their revision and price report. When
+// the outcome is unknown (a write concern error, a timeout, a lost
+// connection) the saved rows are looked up and finished before the error is
+// returned, so none is left without its report.
+func insertImportBatch(ctx context.Context, batch []importedProduct, reputation float64, report *ImportReport) error {
+	docs := make([]interface{}, len(batch))
+	for i := range batch {
+		batch[i].product.ID = primitive.NewObjectID()
+		batch[i].assessment = prepareNewProduct(ctx, &batch[i].product, reputation)
+		docs[i] = batch[i].product
+	}
+
+	failed := map[int]bool{}
+	_, err := productCollection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	isBulkErr := errors.As(err, &bulkErr)
+	if isBulkErr {
+		for _, writeErr := range bulkErr.WriteErrors {
+			failed[writeErr.Index] = true
+			report.Errors = append(report.Errors, ImportRowError{Row: batch[writeErr.Index].line, Error: "not saved: " + writeErr.Message})
+		}
+	}
+
+	var batchErr error
+	if err != nil && (!isBulkErr || bulkErr.WriteConcernError != nil) {
+		batchErr = err
+		// The import context may be what failed
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+
+		saved, lookupErr := savedProductIDs(ctx, batch)
+		if lookupErr != nil {
+			return fmt.Errorf("%w (could not check which rows were saved: %v)", err, lookupErr)
+		}
+		for i := range batch {
+			if !failed[i] && !saved[batch[i].product.ID] {
+				failed[i] = true
+				report.Errors = append(report.Errors, ImportRowError{Row: batch[i].line, Error: "not saved: " + err.Error()})
+			}
+		}
+	}
+
+	for i := range batch {
+		if failed[i] {
+			continue
+		}
+		product := &batch[i].product
+		recordNewProduct(ctx, product, batch[i].assessment, reputation)
+		report.Inserted++
+		if product.Status != model.ReportStatusPublished {
+			report.HeldForReview++
+		}
+	}
+	return batchErr
+}
+
+// savedProductIDs reports which of a batch's products are in the database
+func savedProductIDs(ctx context.Context, batch []importedProduct) (map[primitive.ObjectID]bool, error) {
+	ids := make(bson.A, len(batch))
+	for i := range batch {
+		ids[i] = batch[i].product.ID
+	}
+	cursor, err := productCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	saved := make(map[primitive.ObjectID]bool, len(docs))
+	for _, doc := range docs {
+		saved[doc.ID] = true
+	}
+	return saved, nil
+}
+
+func sortImportErrors(errs []ImportRowError) {
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Row < errs[j].Row })
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func withCategories(t *testing.T, slugs ...string) {
+	nodes := map[string]model.Category{}
+	for _, slug := range slugs {
+		nodes[slug] = model.Category{Slug: slug, Name: slug}
+	}
+	categories.mu.Lock()
+	saved := categories.nodes
+	categories.nodes = nodes
+	categories.mu.Unlock()
+	t.Cleanup(func() {
+		categories.mu.Lock()
+		categories.nodes = saved
+		categories.mu.Unlock()
+	})
+}
+
+func withMaxImportRows(t *testing.T, limit int) {
+	saved := config.MaxImportRows
+	config.MaxImportRows = limit
+	t.Cleanup(func() { config.MaxImportRows = saved })
+}
+
+func mustImportColumns(t *testing.T, mapping map[string]string) map[string]string {
+	t.Helper()
+	columns, err := importColumns(mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return columns
+}
+
+func problemFields(problems []ImportRowError) []string {
+	fields := make([]string, len(problems))
+	for i, problem := range problems {
+		fields[i] = problem.Field
+	}
+	return fields
+}
+
+func TestImportColumns(t *testing.T) {
+	columns := mustImportColumns(t, map[string]string{"price": " Price (₦) ", "area": "Where"})
+	if columns["price"] != "price (₦)" || columns["area"] != "where" || columns["name"] != "name" {
+		t.Errorf("columns = %v", columns)
+	}
+
+	var filterErr *FilterError
+	if _, err := importColumns(map[string]string{"status": "state"}); !errors.As(err, &filterErr) {
+		t.Errorf("mapping an unknown field: err = %v, want a FilterError", err)
+	}
+}
+
+func TestReadCSVRowsMapsHeaderColumns(t *testing.T) {
+	withMaxImportRows(t, 10)
+	columns := mustImportColumns(t, map[string]string{"name": "Item", "price": "Cost (NGN)", "area": "Where"})
+	csv := "Item, Cost (NGN),WHERE,Unit\n" +
+		"Ofada rice, 1500,,kg\n" +
+		"\"Beans, brown\",900\n"
+
+	report := &ImportReport{}
+	rows, err := readCSVRows(strings.NewReader(csv), columns, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows != 2 || len(rows) != 2 || len(report.Errors) != 0 {
+		t.Fatalf("rows = %+v, report = %+v", rows, report)
+	}
+	if rows[0].line != 2 || rows[1].line != 3 {
+		t.Errorf("lines = %d, %d, want 2, 3", rows[0].line, rows[1].line)
+	}
+	if rows[1].values["item"] != "Beans, brown" || rows[1].values["unit"] != "" {
+		t.Errorf("short row values = %v", rows[1].values)
+	}
+
+	// The mapped columns fill the product; the row only lacks an area
+	product, problems := productFromRow(context.Background(), rows[0], columns, primitive.NewObjectID(), "NGN")
+	if fields := problemFields(problems); len(fields) != 1 || fields[0] != "area" {
+		t.Fatalf("problems = %+v, want only area", problems)
+	}
+	price, _ := model.ParseMoney("1500", "NGN")
+	if product.Name != "Ofada rice" || product.Price != price || product.Unit != "kg" || product.Quantity != 1 {
+		t.Errorf("product = %+v", product)
+	}
+}
+
+func TestReadCSVRowsRejectsHeaders(t *testing.T) {
+	withMaxImportRows(t, 10)
+	columns := mustImportColumns(t, nil)
+	tests := map[string]string{
+		"empty":          "",
+		"no name column": "price,area\n100,Yaba\n",
+		"no price":       "name,area\nRice,Yaba\n",
+		"no place":       "name,price,unit\nRice,100,kg\n",
+	}
+
+	for name, csv := range tests {
+		t.Run(name, func(t *testing.T) {
+			var filterErr *FilterError
+			if _, err := readCSVRows(strings.NewReader(csv), columns, &ImportReport{}); !errors.As(err, &filterErr) {
+				t.Errorf("err = %v, want a FilterError", err)
+			}
+		})
+	}
+}
+
+func TestReadCSVRowsReportsBadRecords(t *testing.T) {
+	withMaxImportRows(t, 10)
+	csv := "name,price,area\n" +
+		"Rice,100,Yaba\n" +
+		"\"Beans,200,Yaba\n"
+
+	report := &ImportReport{}
+	rows, err := readCSVRows(strings.NewReader(csv), mustImportColumns(t, nil), report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || report.Rows != 2 || len(report.Errors) != 1 || report.Errors[0].Row != 3 {
+		t.Errorf("rows = %+v, report = %+v", rows, report)
+	}
+}
+
+func TestReadNDJSONRows(t *testing.T) {
+	withMaxImportRows(t, 10)
+	ndjson := `{"Name": " Rice ", "price": 1500.5, "quantity": null, "area": "Yaba"}` + "\n" +
+		"\n" +
+		`{"name": "Beans", "price": 1e21, "organic": true}` + "\n" +
+		`{"name": "Garri", "price": }` + "\n"
+
+	report := &ImportReport{}
+	rows, err := readNDJSONRows(strings.NewReader(ndjson), report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows != 3 || len(rows) != 2 {
+		t.Fatalf("rows = %+v, report = %+v", rows, report)
+	}
+
+	first := rows[0]
+	if first.line != 1 || first.values["name"] != "Rice" || first.values["price"] != "1500.5" {
+		t.Errorf("first row = %+v", first)
+	}
+	if _, ok := first.values["quantity"]; ok {
+		t.Errorf("null quantity was kept as %q", first.values["quantity"])
+	}
+
+	second := rows[1]
+	if second.line != 3 || second.values["price"] != "1000000000000000000000" || second.values["organic"] != "true" {
+		t.Errorf("second row = %+v", second)
+	}
+
+	if len(report.Errors) != 1 || report.Errors[0].Row != 4 || !strings.HasPrefix(report.Errors[0].Error, "invalid JSON") {
+		t.Errorf("errors = %+v", report.Errors)
+	}
+}
+
+func TestImportRowCap(t *testing.T) {
+	withMaxImportRows(t, 2)
+	columns := mustImportColumns(t, nil)
+	header := "name,price,area\n"
+	row := "Rice,100,Yaba\n"
+	object := `{"name": "Rice", "price": 100, "area": "Yaba"}` + "\n"
+
+	if rows, err := readCSVRows(strings.NewReader(header+row+row), columns, &ImportReport{}); err != nil || len(rows) != 2 {
+		t.Errorf("CSV at the cap = %d rows, %v", len(rows), err)
+	}
+	if rows, err := readNDJSONRows(strings.NewReader(object+"\n"+object), &ImportReport{}); err != nil || len(rows) != 2 {
+		t.Errorf("NDJSON at the cap = %d rows, %v", len(rows), err)
+	}
+
+	var filterErr *FilterError
+	if _, err := readCSVRows(strings.NewReader(header+row+row+row), columns, &ImportReport{}); !errors.As(err, &filterErr) {
+		t.Errorf("CSV over the cap: err = %v, want a FilterError", err)
+	}
+	if _, err := readNDJSONRows(strings.NewReader(object+object+object), &ImportReport{}); !errors.As(err, &filterErr) {
+		t.Errorf("NDJSON over the cap: err = %v, want a FilterError", err)
+	}
+}
+
+func TestProductFromRowProblems(t *testing.T) {
+	withCategories(t, "grains")
+	columns := mustImportColumns(t, nil)
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	// Every row has a problem, so none reaches the location lookup
+	tests := []struct {
+		name   string
+		values map[string]string
+		want   []string
+	}{
+		{"missing name", map[string]string{"price": "100", "area": "Yaba"}, []string{"name"}},
+		{"missing price", map[string]string{"name": "Rice", "area": "Yaba"}, []string{"price"}},
+		{"missing name and price", map[string]string{"area": "Yaba"}, []string{"name", "price"}},
+		{"bad price", map[string]string{"name": "Rice", "price": "cheap", "area": "Yaba"}, []string{"price"}},
+		{"unknown currency", map[string]string{"name": "Rice", "price": "100", "currency": "??", "area": "Yaba"}, []string{"price"}},
+		{"quantity without unit", map[string]string{"name": "Rice", "price": "100", "quantity": "2", "area": "Yaba"}, []string{"unit"}},
+		{"unknown unit", map[string]string{"name": "Rice", "price": "100", "unit": "handful", "area": "Yaba"}, []string{"unit"}},
+		{"bad quantity", map[string]string{"name": "Rice", "price": "100", "quantity": "-1", "unit": "kg", "area": "Yaba"}, []string{"quantity"}},
+		{"unknown category", map[string]string{"name": "Rice", "price": "100", "category": "gadgets", "area": "Yaba"}, []string{"category"}},
+		{"lat without lng", map[string]string{"name": "Rice", "price": "100", "lat": "6.5", "area": "Yaba"}, []string{"lat"}},
+		{"lng without lat", map[string]string{"name": "Rice", "price": "100", "lng": "3.3", "area": "Yaba"}, []string{"lat"}},
+		{"text lat", map[string]string{"name": "Rice", "price": "100", "lat": "north", "lng": "3.3", "area": "Yaba"}, []string{"lat"}},
+		{"NaN lat", map[string]string{"name": "Rice", "price": "100", "lat": "NaN", "lng": "3.3", "area": "Yaba"}, []string{"lat"}},
+		{"infinite lng", map[string]string{"name": "Rice", "price": "100", "lat": "6.5", "lng": "Inf", "area": "Yaba"}, []string{"lat"}},
+		{"lat out of range", map[string]string{"name": "Rice", "price": "100", "lat": "95", "lng": "3.3", "area": "Yaba"}, []string{"lat"}},
+		{"bad date", map[string]string{"name": "Rice", "price": "100", "date": "12/01/2024", "area": "Yaba"}, []string{"date"}},
+		{"future date", map[string]string{"name": "Rice", "price": "100", "date": tomorrow, "area": "Yaba"}, []string{"date"}},
+		{"bad market id", map[string]string{"name": "Rice", "price": "100", "market_id": "oshodi"}, []string{"market_id"}},
+		{"bad location id", map[string]string{"name": "Rice", "price": "100", "location_id": "yaba"}, []string{"location_id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := importRow{line: 7, values: tt.values}
+			_, problems := productFromRow(context.Background(), row, columns, primitive.NewObjectID(), "NGN")
+			got := problemFields(problems)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("problems = %+v, want fields %v", problems, tt.want)
+			}
+			for _, problem := range problems {
+				if problem.Row != 7 {
+					t.Errorf("problem %+v is not on row 7", problem)
+				}
+			}
+		})
+	}
+}
+
+func TestProductFromRowFields(t *testing.T) {
+	withCategories(t, "grains")
+	columns := mustImportColumns(t, nil)
+	userID := primitive.NewObjectID()
+
+	// No area, so the only problem is the missing place and no lookup runs
+	row := importRow{line: 2, values: map[string]string{
+		"name":     "Rice",
+		"price":    "2500.50",
+		"quantity": "50",
+		"unit":     "kg",
+		"category": "grains",
+		"lat":      "6.5244",
+		"lng":      "3.3792",
+		"date":     "2024-03-01",
+	}}
+	product, problems := productFromRow(context.Background(), row, columns, userID, "NGN")
+	if fields := problemFields(problems); len(fields) != 1 || fields[0] != "area" {
+		t.Fatalf("problems = %+v, want only area", problems)
+	}
+
+	price, _ := model.ParseMoney("2500.50", "NGN")
+	if product.UserID != userID || product.Price != price || product.Quantity != 50 || product.Unit != "kg" || product.Category != "grains" {
+		t.Errorf("product = %+v", product)
+	}
+	if product.Location == nil || product.Location.Coordinates[0] != 3.3792 || product.Location.Coordinates[1] != 6.5244 {
+		t.Errorf("location = %+v", product.Location)
+	}
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC); product.CreatedAt == nil || !product.CreatedAt.Equal(want) {
+		t.Errorf("created_at = %v, want %v", product.CreatedAt, want)
+	}
+
+	// A row's own currency wins over the import's
+	row = importRow{line: 3, values: map[string]string{"name": "Rice", "price": "12", "currency": "GHS"}}
+	product, _ = productFromRow(context.Background(), row, columns, userID, "NGN")
+	if product.Price.Currency != "GHS" {
+		t.Errorf("currency = %s, want GHS", product.Price.Currency)
+	}
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Listing sort options accepted on GET /products
+const (
+	SortNewest    = "newest"
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	// Confidence changes with votes and decays with age, so paging by it is
+	// best-effort: a listing whose score moves between pages can be skipped
+	// or seen twice
+	SortConfidence = "confidence"
+	// Unit-price sorts need a basis (ProductFilter.UnitBasis) such as kg
+	SortUnitPriceAsc  = "unit_price_asc"
+	SortUnitPriceDesc = "unit_price_desc"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort option")
+)
+
+// FilterError marks a rejected listing filter so handlers can answer 400
+type FilterError struct {
+	Err error
+}
+
+func (e *FilterError) Error() string { return e.Err.Error() }
+
+func (e *FilterError) Unwrap() error { return e.Err }
+
+// sortSpec describes the key a listing is ordered by. Every sort uses _id as
+// the tie-breaker so the cursor always points at exactly one document.
+type sortSpec struct {
+	field     string
+	direction int
+	isTime    bool
+	value     func(p model.Product) float64
+}
+
+func priceKey(p model.Product) float64      { return float64(p.Price.Amount) }
+func confidenceKey(p model.Product) float64 { return p.Confidence }
+
+func unitPriceKey(p model.Product) float64 {
+	if p.UnitPrice == nil {
+		return 0
+	}
+	return float64(p.UnitPrice.Amount)
+}
+
+var productSorts = map[string]sortSpec{
+	SortNewest:     {field: "created_at", direction: -1, isTime: true},
+	SortPriceAsc:   {field: "price.amount", direction: 1, value: priceKey},
+	SortPriceDesc:  {field: "price.amount", direction: -1, value: priceKey},
+	SortConfidence: {field: "confidence", direction: -1, value: confidenceKey},
+
+	SortUnitPriceAsc:  {field: "unit_price.amount", direction: 1, value: unitPriceKey},
+	SortUnitPriceDesc: {field: "unit_price.amount", direction: -1, value: unitPriceKey},
+}
+
+// ProductFilter narrows a listing. Zero values mean "no constraint". Price
+// bounds only compare prices in Currency; amounts in other currencies are
+// never mixed in.
+type ProductFilter struct {
+	Categories    []string // each includes its subcategories
+	Area          string
+	LocationID    primitive.ObjectID // the location or anywhere inside it
+	MarketID      primitive.ObjectID
+	OpenNow       bool // only listings at markets open right now
+	Currency      string
+	UnitBasis     string // only listings with a unit price per this unit
+	MinPrice      *model.Money
+	MaxPrice      *model.Money
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	SellerID      primitive.ObjectID
+	MinConfidence *float64
+}
+
+// Validate checks that ranges are well formed
+func (f ProductFilter) Validate() error {
+	for _, bound := range []*model.Money{f.MinPrice, f.MaxPrice} {
+		if bound == nil {
+			continue
+		}
+		if bound.Amount < 0 {
+			return errors.New("price bounds must not be negative")
+		}
+		if bound.Currency != f.Currency {
+			return fmt.Errorf("price bounds must be in %s", f.Currency)
+		}
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil && f.MinPrice.Amount > f.MaxPrice.Amount {
+		return errors.New("min_price must not exceed max_price")
+	}
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && !f.CreatedAfter.Before(*f.CreatedBefore) {
+		return errors.New("created_after must be before created_before")
+	}
+	if f.MinConfidence != nil && !(*f.MinConfidence >= 0 && *f.MinConfidence <= 1) {
+		return errors.New("min_confidence must be between 0 and 1")
+	}
+	return nil
+}
+
+// toBSON turns the filter into a Mongo query document. Reports held for
+// review, listings hidden by moderators and deleted listings are never part
+// of public reads.
+func (f ProductFilter) toBSON() bson.M {
+	filter := bson.M{
+		"status":     bson.M{"$ne": model.ReportStatusPending},
+		"hidden":     bson.M{"$ne": true},
+		"deleted_at": nil,
+	}
+
+	// A parent category matches listings filed under any of its children
+	categories := expandCategories(f.Categories)
+	if len(categories) == 1 {
+		filter["category"] = categories[0]
+	} else if len(categories) > 1 {
+		filter["category"] = bson.M{"$in": categories}
+	}
+	if f.Area != "" {
+		filter["area"] = f.Area
+	}
+	if !f.LocationID.IsZero() {
+		filter["location_path"] = f.LocationID
+	}
+	if f.OpenNow {
+		open := OpenMarketIDs(time.Now())
+		if !f.MarketID.IsZero() {
+			open = []primitive.ObjectID{}
+			if marketOpenAt(f.MarketID, time.Now()) {
+				open = append(open, f.MarketID)
+			}
+		}
+		filter["market_id"] = bson.M{"$in": open}
+	} else if !f.MarketID.IsZero() {
+		filter["market_id"] = f.MarketID
+	}
+
+	if f.Currency != "" {
+		filter["price.currency"] = f.Currency
+	}
+	if f.UnitBasis != "" {
+		filter["unit_price.per"] = f.UnitBasis
+	}
+	price := bson.M{}
+	if f.MinPrice != nil {
+		price["$gte"] = f.MinPrice.Amount
+	}
+	if f.MaxPrice != nil {
+		price["$lte"] = f.MaxPrice.Amount
+	}
+	if len(price) > 0 {
+		filter["price.amount"] = price
+	}
+
+	created := bson.M{}
+	if f.CreatedAfter != nil {
+		created["$gte"] = *f.CreatedAfter
+	}
+	if f.CreatedBefore != nil {
+		created["$lt"] = *f.CreatedBefore
+	}
+	if len(created) > 0 {
+		filter["created_at"] = created
+	}
+
+	if !f.SellerID.IsZero() {
+		filter["user_id"] = f.SellerID
+	}
+	if f.MinConfidence != nil {
+		filter["confidence"] = bson.M{"$gte": *f.MinConfidence}
+	}
+
+	return filter
+}
+
+// Matches applies the filter to an in-memory product
+func (f ProductFilter) Matches(p model.Product) bool {
+	if p.Status == model.ReportStatusPending || p.Hidden || p.DeletedAt != nil {
+		return false
+	}
+	if len(f.Categories) > 0 {
+		found := false
+		for _, category := range expandCategories(f.Categories) {
+			if p.Category == category {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Area != "" && p.Area != f.Area {
+		return false
+	}
+	if !f.LocationID.IsZero() && !containsID(p.LocationPath, f.LocationID) {
+		return false
+	}
+	if !f.MarketID.IsZero() && (p.MarketID == nil || *p.MarketID != f.MarketID) {
+		return false
+	}
+	if f.OpenNow && (p.MarketID == nil || !marketOpenAt(*p.MarketID, time.Now())) {
+		return false
+	}
+	if f.Currency != "" && p.Price.Currency != f.Currency {
+		return false
+	}
+	if f.UnitBasis != "" && (p.UnitPrice == nil || p.UnitPrice.Per != f.UnitBasis) {
+		return false
+	}
+	if f.MinPrice != nil && p.Price.Amount < f.MinPrice.Amount {
+		return false
+	}
+	if f.MaxPrice != nil && p.Price.Amount > f.MaxPrice.Amount {
+		return false
+	}
+	if f.CreatedAfter != nil && (p.CreatedAt == nil || p.CreatedAt.Before(*f.CreatedAfter)) {
+		return false
+	}
+	if f.CreatedBefore != nil && (p.CreatedAt == nil || !p.CreatedAt.Before(*f.CreatedBefore)) {
+		return false
+	}
+	if !f.SellerID.IsZero() && p.UserID != f.SellerID {
+		return false
+	}
+	if f.MinConfidence != nil && p.Confidence < *f.MinConfidence {
+		return false
+	}
+	return true
+}
+
+type ProductListOptions struct {
+	Filter ProductFilter
+	Sort   string
+	Limit  int
+	Cursor string
+}
+
+type ProductPage struct {
+	Products   []model.Product `json:"products"`
+	NextCursor string          `json:"next_cursor"`
+}
+
+// listCursor is the decoded form of the opaque next_cursor token.
+type listCursor struct {
+	Sort string             `json:"s"`
+	Time *time.Time         `json:"t,omitempty"`
+	Num  float64            `json:"n,omitempty"`
+	ID   primitive.ObjectID `json:"id"`
+}
+
+func encodeCursor(cur listCursor) string {
+	raw, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(token string) (listCursor, error) {
+	var cur listCursor
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cur, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &cur); err != nil || cur.ID.IsZero() {
+		return cur, ErrInvalidCursor
+	}
+	return cur, nil
+}
+
+// cursorFor captures the sort key of the last product on a page
+func cursorFor(sortName string, spec sortSpec, p model.Product) listCursor {
+	cur := listCursor{Sort: sortName, ID: p.ID}
+	switch {
+	case spec.isTime:
+		cur.Time = p.CreatedAt
+	default:
+		cur.Num = spec.value(p)
+	}
+	return cur
+}
+
+// afterCursor builds the keyset condition for "documents after the cursor"
+func afterCursor(spec sortSpec, cur listCursor) bson.M {
+	op := "$gt"
+	if spec.direction < 0 {
+		op = "$lt"
+	}
+	if spec.isTime {
+		return afterTimeCursor(spec, cur, op)
+	}
+
+	return bson.M{"$or": bson.A{
+		bson.M{spec.field: bson.M{op: cur.Num}},
+		bson.M{spec.field: cur.Num, "_id": bson.M{op: cur.ID}},
+	}}
+}
+
+// afterTimeCursor is afterCursor for a time that legacy documents may lack.
+// Mongo sorts a missing time before every date, but a date comparison never
+// matches it, so those documents are added explicitly on the side of the
+// order they fall on.
+func afterTimeCursor(spec sortSpec, cur listCursor, op string) bson.M {
+	if cur.Time == nil {
+		after := bson.A{bson.M{spec.field: nil, "_id": bson.M{op: cur.ID}}}
+		if spec.direction > 0 {
+			after = append(after, bson.M{spec.field: bson.M{"$ne": nil}})
+		}
+		return bson.M{"$or": after}
+	}
+
+	after := bson.A{
+		bson.M{spec.field: bson.M{op: *cur.Time}},
+		bson.M{spec.field: *cur.Time, "_id": bson.M{op: cur.ID}},
+	}
+	if spec.direction < 0 {
+		after = append(after, bson.M{spec.field: nil})
+	}
+	return bson.M{"$or": after}
+}
+
+// ✅ Public marketplace listing with keyset pagination
+func ListProducts(opts ProductListOptions) (*ProductPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if opts.Sort == "" {
+		opts.Sort = SortNewest
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = config.ProductPageSize
+	}
+	if opts.Limit > config.MaxProductPageSize {
+		opts.Limit = config.MaxProductPageSize
+	}
+	spec, ok := productSorts[opts.Sort]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+	// Ordering by amount is only meaningful within one currency, and unit
+	// prices only within one basis
+	byUnitPrice := opts.Sort == SortUnitPriceAsc || opts.Sort == SortUnitPriceDesc
+	if opts.Filter.Currency == "" && (byUnitPrice || opts.Sort == SortPriceAsc || opts.Sort == SortPriceDesc) {
+		opts.Filter.Currency = config.DefaultCurrency
+	}
+	if byUnitPrice && opts.Filter.UnitBasis == "" {
+		return nil, &FilterError{Err: errors.New("per is required to sort by unit price (e.g. per=kg)")}
+	}
+
+	if err := opts.Filter.Validate(); err != nil {
+		return nil, &FilterError{Err: err}
+	}
+
+	filter := opts.Filter.toBSON()
+	if opts.Cursor != "" {
+		cur, err := decodeCursor(opts.Cursor)
+		if err != nil || cur.Sort != opts.Sort {
+			return nil, ErrInvalidCursor
+		}
+		filter = bson.M{"$and": bson.A{filter, afterCursor(spec, cur)}}
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: spec.field, Value: spec.direction}, {Key: "_id", Value: spec.direction}}).
+		SetLimit(int64(opts.Limit + 1))
+
+	cursor, err := productCollection.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	products := []model.Product{}
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	page := &ProductPage{Products: products}
+	if len(products) > opts.Limit {
+		page.Products = products[:opts.Limit]
+		last := page.Products[len(page.Products)-1]
+		page.NextCursor = encodeCursor(cursorFor(opts.Sort, spec, last))
+	}
+
+	return page, nil
+}
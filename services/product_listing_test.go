@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 10, 30, 15, 0, time.UTC)
+	tests := []listCursor{
+		{Sort: SortNewest, Time: &created, ID: primitive.NewObjectID()},
+		{Sort: SortNewest, ID: primitive.NewObjectID()},
+		{Sort: SortPriceAsc, Num: 150050, ID: primitive.NewObjectID()},
+		{Sort: SortConfidence, Num: 0.875, ID: primitive.NewObjectID()},
+	}
+
+	for _, want := range tests {
+		got, err := decodeCursor(encodeCursor(want))
+		if err != nil {
+			t.Fatalf("decodeCursor(%+v): %v", want, err)
+		}
+		sameTime := (got.Time == nil) == (want.Time == nil) && (got.Time == nil || got.Time.Equal(*want.Time))
+		if got.Sort != want.Sort || got.Num != want.Num || got.ID != want.ID || !sameTime {
+			t.Errorf("round trip = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	tests := map[string]string{
+		"not base64":  "!!not-a-cursor!!",
+		"not json":    encode("hello"),
+		"zero id":     encodeCursor(listCursor{Sort: SortNewest}),
+		"missing id":  encode(`{"s":"newest"}`),
+		"bad id":      encode(`{"s":"newest","id":"xyz"}`),
+		"wrong shape": encode(`["newest"]`),
+	}
+
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := decodeCursor(token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("err = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestListProductsRejectsCursorFromAnotherSort(t *testing.T) {
+	created := time.Now()
+	token := encodeCursor(cursorFor(SortNewest, productSorts[SortNewest], model.Product{ID: primitive.NewObjectID(), CreatedAt: &created}))
+
+	// Rejected before any query runs
+	for _, sort := range []string{SortPriceAsc, SortPriceDesc, SortConfidence} {
+		if _, err := ListProducts(ProductListOptions{Sort: sort, Cursor: token}); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("sort %s with a newest cursor: err = %v, want ErrInvalidCursor", sort, err)
+		}
+	}
+}
+
+func TestCursorFor(t *testing.T) {
+	created := time.Now()
+	unitPrice := model.UnitPrice{Money: model.Money{Amount: 120000, Currency: "NGN"}, Per: "kg"}
+	p := model.Product{
+		ID:         primitive.NewObjectID(),
+		CreatedAt:  &created,
+		Price:      model.Money{Amount: 250000, Currency: "NGN"},
+		UnitPrice:  &unitPrice,
+		Confidence: 0.75,
+	}
+
+	tests := []struct {
+		sort string
+		want listCursor
+	}{
+		{SortNewest, listCursor{Sort: SortNewest, Time: &created, ID: p.ID}},
+		{SortPriceDesc, listCursor{Sort: SortPriceDesc, Num: 250000, ID: p.ID}},
+		{SortUnitPriceAsc, listCursor{Sort: SortUnitPriceAsc, Num: 120000, ID: p.ID}},
+		{SortConfidence, listCursor{Sort: SortConfidence, Num: 0.75, ID: p.ID}},
+	}
+
+	for _, tt := range tests {
+		if got := cursorFor(tt.sort, productSorts[tt.sort], p); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("cursorFor(%s) = %+v, want %+v", tt.sort, got, tt.want)
+		}
+	}
+}
+
+func TestAfterCursor(t *testing.T) {
+	id := primitive.NewObjectID()
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	ascending := sortSpec{field: "created_at", direction: 1, isTime: true}
+
+	tests := []struct {
+		name string
+		spec sortSpec
+		cur  listCursor
+		want bson.M
+	}{
+		{
+			"ascending ties on _id",
+			productSorts[SortPriceAsc],
+			listCursor{Num: 500, ID: id},
+			bson.M{"$or": bson.A{
+				bson.M{"price.amount": bson.M{"$gt": 500.0}},
+				bson.M{"price.amount": 500.0, "_id": bson.M{"$gt": id}},
+			}},
+		},
+		{
+			"descending ties on _id",
+			productSorts[SortPriceDesc],
+			listCursor{Num: 500, ID: id},
+			bson.M{"$or": bson.A{
+				bson.M{"price.amount": bson.M{"$lt": 500.0}},
+				bson.M{"price.amount": 500.0, "_id": bson.M{"$lt": id}},
+			}},
+		},
+		{
+			// Undated legacy listings sort after every date
+			"newest keeps undated listings after a dated cursor",
+			productSorts[SortNewest],
+			listCursor{Time: &created, ID: id},
+			bson.M{"$or": bson.A{
+				bson.M{"created_at": bson.M{"$lt": created}},
+				bson.M{"created_at": created, "_id": bson.M{"$lt": id}},
+				bson.M{"created_at": nil},
+			}},
+		},
+		{
+			"newest from an undated cursor",
+			productSorts[SortNewest],
+			listCursor{ID: id},
+			bson.M{"$or": bson.A{
+				bson.M{"created_at": nil, "_id": bson.M{"$lt": id}},
+			}},
+		},
+		{
+			"oldest first from a dated cursor",
+			ascending,
+			listCursor{Time: &created, ID: id},
+			bson.M{"$or": bson.A{
+				bson.M{"created_at": bson.M{"$gt": created}},
+				bson.M{"created_at": created, "_id": bson.M{"$gt": id}},
+			}},
+		},
+		{
+			"oldest first from an undated cursor",
+			ascending,
+			listCursor{ID: id},
+			bson.M{"$or": bson.A{
+				bson.M{"created_at": nil, "_id": bson.M{"$gt": id}},
+				bson.M{"created_at": bson.M{"$ne": nil}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := afterCursor(tt.spec, tt.cur); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("afterCursor = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestListProductsPagesPastUndatedListings needs a MongoDB server in
+// TEST_MONGO_URI
+func TestListProductsPagesPastUndatedListings(t *testing.T) {
+	testDatabase(t)
+	ctx := context.Background()
+
+	now := time.Now().Truncate(time.Millisecond)
+	earlier := now.Add(-time.Hour)
+	var want []primitive.ObjectID
+	for _, created := range []*time.Time{&now, &earlier, &earlier, nil, nil} {
+		p := model.Product{ID: primitive.NewObjectID(), Name: "Rice", Status: model.ReportStatusPublished, CreatedAt: created}
+		if _, err := productCollection.InsertOne(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, p.ID)
+	}
+	// Equal times fall back to _id, newest first
+	want[1], want[2] = want[2], want[1]
+	want[3], want[4] = want[4], want[3]
+
+	var got []primitive.ObjectID
+	opts := ProductListOptions{Limit: 1}
+	for page := 0; page <= len(want); page++ {
+		result, err := ListProducts(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range result.Products {
+			got = append(got, p.ID)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		opts.Cursor = result.NextCursor
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("paged %v, want %v", got, want)
+	}
+}
@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"CROWD_MARKET/config"
@@ -16,27 +18,57 @@ import (
 
 var productCollection *mongo.Collection
 
+var ErrNotRestorable = errors.New("no deleted product to restore")
+
+var ErrFieldNotEditable = errors.New("field cannot be updated")
+
+var (
+	ErrInvalidID       = errors.New("invalid ID")
+	ErrProductNotFound = errors.New("product not found")
+	ErrNotOwner        = errors.New("product is not owned by user")
+)
+
+// ownerEditableFields are the stored fields an owner may change. Status,
+// visibility, votes, confidence and deletion markers are managed by the
+// server and never pass through an owner update; images change through the
+// image endpoints and ReplacePrimaryImage.
+var ownerEditableFields = map[string]bool{
+	"name":        true,
+	"price":       true,
+	"area":        true,
+	"description": true,
+	"category":    true,
+	"quantity":    true,
+	"unit":        true,
+	"location_id": true,
+	"market_id":   true,
+	"location":    true,
+}
+
 func InitProductService() {
 	productCollection = config.DB.Collection("products")
+	ensureIndexes(productCollection, productIndexes())
+	ProductSearch = NewMongoProductSearcher(productCollection)
 }
 
 // Helper: Convert string to ObjectID
 func toObjectID(id string) (primitive.ObjectID, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return primitive.NilObjectID, errors.New("invalid ID")
+		return primitive.NilObjectID, ErrInvalidID
 	}
 	return objID, nil
 }
 
-// Helper: Filter by ID and optionally user ownership
+// Helper: Filter by ID and optionally user ownership. Soft-deleted
+// products never match.
 func buildFilter(productID, userID string) (bson.M, error) {
 	objID, err := toObjectID(productID)
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{"_id": objID}
+	filter := bson.M{"_id": objID, "deleted_at": nil}
 
 	if userID != "" {
 		userObjID, err := toObjectID(userID)
@@ -49,20 +81,74 @@ func buildFilter(productID, userID string) (bson.M, error) {
 	return filter, nil
 }
 
-// ✅ Add a new product
+// ✅ Add a new product. The price is scored against recent reports for the
+// same item and area; flagged listings are saved with a suspect status.
 func AddProduct(product model.Product) (model.Product, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if err := applyProductMarket(&product); err != nil {
+		return model.Product{}, err
+	}
+	if err := applyProductLocation(ctx, &product); err != nil {
+		return model.Product{}, err
+	}
+
+	reputation := userReputation(ctx, product.UserID)
+	assessment := prepareNewProduct(ctx, &product, reputation)
+
 	result, err := productCollection.InsertOne(ctx, product)
 	if err != nil {
 		return model.Product{}, err
 	}
 
 	product.ID = result.InsertedID.(primitive.ObjectID)
+	recordNewProduct(ctx, &product, assessment, reputation)
+
 	return product, nil
 }
 
+// prepareNewProduct links a validated product to its item and fills in the
+// fields derived on creation: status, confidence and unit price
+func prepareNewProduct(ctx context.Context, product *model.Product, reputation float64) PriceAssessment {
+	assessment := PriceAssessment{Status: model.ReportStatusPublished}
+	item, err := FindOrCreateItem(ctx, product.Name, product.Category)
+	if err != nil {
+		// The listing is still saved; the item migration links it later
+		log.Printf("⚠️ Failed to resolve item for %q: %v", product.Name, err)
+	} else {
+		product.ItemID = item.ID
+		assessment = assessPrice(ctx, item.ID, product.Area, product.Price, primitive.NilObjectID)
+	}
+
+	// Low-reputation contributors are held for review unless already flagged
+	if assessment.Status == model.ReportStatusPublished && reputation < config.ReviewReputationThreshold {
+		assessment.Status = model.ReportStatusPending
+	}
+	product.Status = assessment.Status
+	product.Confidence = ComputeConfidence(0, 0, 0, reputation)
+	product.UnitPrice = productUnitPrice(*product)
+	return assessment
+}
+
+// recordNewProduct writes the creation revision and price report of a
+// freshly inserted product
+func recordNewProduct(ctx context.Context, product *model.Product, assessment PriceAssessment, reputation float64) {
+	recordRevision(ctx, product.ID, product.UserID, model.RevisionCreate, []model.FieldChange{
+		{Field: "name", New: product.Name},
+		{Field: "price", New: product.Price},
+		{Field: "area", New: product.Area},
+		{Field: "category", New: product.Category},
+	})
+
+	if !product.ItemID.IsZero() {
+		recordPriceReport(ctx, product, assessment, reputation)
+	}
+	if assessment.Score != nil {
+		applyReputationEventAsync(product.UserID, ReputationEvent{ConsensusError: assessment.Score})
+	}
+}
+
 // ✅ Get all products owned by a specific user
 func GetAllProducts(userID string) ([]model.Product, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -73,7 +159,7 @@ func GetAllProducts(userID string) ([]model.Product, error) {
 		return nil, errors.New("invalid user ID")
 	}
 
-	filter := bson.M{"user_id": userObjID}
+	filter := bson.M{"user_id": userObjID, "deleted_at": nil}
 
 	cursor, err := productCollection.Find(ctx, filter)
 	if err != nil {
@@ -107,7 +193,7 @@ func GetProductByID(id string) (*model.Product, error) {
 	err = productCollection.FindOne(ctx, filter).Decode(&product)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("product not found")
+			return nil, ErrProductNotFound
 		}
 		return nil, err
 	}
@@ -115,8 +201,45 @@ func GetProductByID(id string) (*model.Product, error) {
 	return &product, nil
 }
 
-// ✅ Update a product (ensures ownership)
+// notOwnedError explains why no live product of the user matched id:
+// ErrProductNotFound if there is none at all, ErrNotOwner otherwise
+func notOwnedError(ctx context.Context, id primitive.ObjectID) error {
+	count, err := productCollection.CountDocuments(ctx, bson.M{"_id": id, "deleted_at": nil})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrProductNotFound
+	}
+	return ErrNotOwner
+}
+
+// findAnyProduct loads a product by ID whether or not it was soft-deleted
+func findAnyProduct(ctx context.Context, id string) (*model.Product, error) {
+	objID, err := toObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+	var product model.Product
+	err = productCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// ✅ Update a product (ensures ownership). Changed fields are recorded as a
+// revision with their previous values.
 func UpdateProductByUser(id string, userID string, fields map[string]interface{}) (*model.Product, error) {
+	for key := range fields {
+		if !ownerEditableFields[key] {
+			return nil, fmt.Errorf("%w: %s", ErrFieldNotEditable, key)
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -125,25 +248,117 @@ func UpdateProductByUser(id string, userID string, fields map[string]interface{}
 		return nil, err
 	}
 
+	if err := marketUpdate(fields); err != nil {
+		return nil, err
+	}
+	unset, err := locationUpdate(ctx, fields)
+	if err != nil {
+		return nil, err
+	}
 	update := bson.M{"$set": fields}
-	result := productCollection.FindOneAndUpdate(
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	var before bson.Raw
+	err = productCollection.FindOneAndUpdate(
 		ctx,
 		filter,
 		update,
-		options.FindOneAndUpdate().SetReturnDocument(options.After),
-	)
-
-	var updated model.Product
-	if err := result.Decode(&updated); err != nil {
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&before)
+	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("product not found or not owned by user")
+			return nil, notOwnedError(ctx, filter["_id"].(primitive.ObjectID))
 		}
 		return nil, err
 	}
 
+	var updated model.Product
+	if err := productCollection.FindOne(ctx, bson.M{"_id": before.Lookup("_id").ObjectID()}).Decode(&updated); err != nil {
+		return nil, err
+	}
+
+	if changes := append(diffFields(before, fields), diffUnset(before, unset)...); len(changes) > 0 {
+		recordRevision(ctx, updated.ID, updated.UserID, model.RevisionUpdate, changes)
+	}
+
+	if touchesUnitPrice(fields) {
+		refreshUnitPrice(ctx, &updated)
+	}
+	if touchesPriceReport(fields) {
+		syncPriceReport(ctx, &updated)
+	}
+
 	return &updated, nil
 }
 
+// touchesPriceReport reports whether an update changes report-relevant fields
+func touchesPriceReport(fields map[string]interface{}) bool {
+	for _, key := range []string{"name", "category", "price", "area", "location", "location_id", "market_id"} {
+		if _, ok := fields[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// touchesUnitPrice reports whether an update changes the normalized unit price
+func touchesUnitPrice(fields map[string]interface{}) bool {
+	for _, key := range []string{"price", "quantity", "unit"} {
+		if _, ok := fields[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshUnitPrice stores the unit price derived from the product's fields
+func refreshUnitPrice(ctx context.Context, product *model.Product) {
+	product.UnitPrice = productUnitPrice(*product)
+	update := bson.M{"$unset": bson.M{"unit_price": ""}}
+	if product.UnitPrice != nil {
+		update = bson.M{"$set": bson.M{"unit_price": product.UnitPrice}}
+	}
+	if _, err := productCollection.UpdateOne(ctx, bson.M{"_id": product.ID}, update); err != nil {
+		log.Printf("⚠️ Failed to update unit price for product %s: %v", product.ID.Hex(), err)
+	}
+}
+
+// 🗑️ Soft-delete a product. It disappears from every read path, its price
+// report stops counting, and its owner can restore it until the restore
+// window passes.
+func SoftDeleteProduct(id string, actorID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter, err := buildFilter(id, "")
+	if err != nil {
+		return err
+	}
+	actorObjID, err := toObjectID(actorID)
+	if err != nil {
+		return errors.New("invalid user ID")
+	}
+
+	now := time.Now()
+	result, err := productCollection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{
+		"deleted_at": now,
+		"deleted_by": actorObjID,
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrProductNotFound
+	}
+	productID := filter["_id"].(primitive.ObjectID)
+	recordRevision(ctx, productID, actorObjID, model.RevisionDelete, []model.FieldChange{
+		{Field: "deleted_at", New: now},
+	})
+	// The report stays for history but no longer counts towards prices
+	return setPriceReportStatus(ctx, productID, model.ReportStatusRemoved)
+}
+
 // ✅ Delete a product (ensures ownership)
 func DeleteProductByUser(id string, userID string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -154,18 +369,91 @@ func DeleteProductByUser(id string, userID string) error {
 		return err
 	}
 
-	result, err := productCollection.DeleteOne(ctx, filter)
+	count, err := productCollection.CountDocuments(ctx, filter)
 	if err != nil {
 		return err
 	}
-	if result.DeletedCount == 0 {
+	if count == 0 {
 		return errors.New("product not found or not owned by user")
 	}
 
-	return nil
+	return SoftDeleteProduct(id, userID)
+}
+
+// ♻️ Restore a soft-deleted product. Only the owner can restore, only
+// within the restore window, and not after a moderator removed it.
+func RestoreProduct(id string, userID string) (*model.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := toObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+	userObjID, err := toObjectID(userID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	var product model.Product
+	err = productCollection.FindOne(ctx, bson.M{"_id": objID, "user_id": userObjID, "deleted_at": bson.M{"$ne": nil}}).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotRestorable
+	}
+	if err != nil {
+		return nil, err
+	}
+	if product.DeletedBy != nil && *product.DeletedBy != userObjID {
+		return nil, errors.New("this product was removed by a moderator and cannot be restored")
+	}
+	if time.Since(*product.DeletedAt) > config.ProductRestoreWindow {
+		return nil, errors.New("the restore window for this product has passed")
+	}
+
+	_, err = productCollection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$unset": bson.M{"deleted_at": "", "deleted_by": ""}})
+	if err != nil {
+		return nil, err
+	}
+	recordRevision(ctx, objID, userObjID, model.RevisionRestore, []model.FieldChange{
+		{Field: "deleted_at", Old: *product.DeletedAt},
+	})
+	product.DeletedAt = nil
+	product.DeletedBy = nil
+	if err := setPriceReportStatus(ctx, objID, reportStatusFor(product)); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// 🗑️ Products the user deleted that can still be restored
+func GetDeletedProducts(userID string) ([]model.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userObjID, err := toObjectID(userID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	cursor, err := productCollection.Find(ctx, bson.M{
+		"user_id":    userObjID,
+		"deleted_by": userObjID,
+		"deleted_at": bson.M{"$gt": time.Now().Add(-config.ProductRestoreWindow)},
+	}, options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	products := []model.Product{}
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
 }
 
-// ✅ Delete product for admin (no ownership check)
+// ✅ Permanently delete a product (no ownership check). Price reports are
+// kept so aggregates retain their history.
 func DeleteProduct(id string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -178,3 +466,47 @@ func DeleteProduct(id string) error {
 	_, err = productCollection.DeleteOne(ctx, bson.M{"_id": objID})
 	return err
 }
+
+// 🧹 Hard-delete products whose restore window has passed, then remove the
+// images no other product uses. Returns how many products were purged.
+func PurgeDeletedProducts() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	cutoff := time.Now().Add(-config.ProductRestoreWindow)
+	cursor, err := productCollection.Find(ctx, bson.M{"deleted_at": bson.M{"$lte": cutoff}})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	purged := 0
+	for cursor.Next(ctx) {
+		var p model.Product
+		if err := cursor.Decode(&p); err != nil {
+			return purged, err
+		}
+		if _, err := productCollection.DeleteOne(ctx, bson.M{"_id": p.ID}); err != nil {
+			return purged, err
+		}
+		// Only once the document is gone; images another product still uses
+		// are kept, and failed deletes are left to the orphaned image collector
+		DeleteUnusedImages(ProductImageURLs(p))
+		purged++
+	}
+	return purged, cursor.Err()
+}
+
+// ⏱️ Purge expired soft-deleted products now and on every tick
+func StartProductPurger(interval time.Duration) {
+	go func() {
+		for {
+			if n, err := PurgeDeletedProducts(); err != nil {
+				log.Printf("⚠️ Product purge failed: %v", err)
+			} else if n > 0 {
+				log.Printf("🧹 Purged %d deleted products", n)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUpdateProductByUserRejectsServerManagedFields(t *testing.T) {
+	const id, userID = "64b7f0c2a1b2c3d4e5f60718", "64b7f0c2a1b2c3d4e5f60719"
+
+	for _, field := range []string{
+		"status", "hidden", "confidence", "confirmations", "disputes",
+		"deleted_at", "deleted_by", "user_id", "_id", "item_id", "unit_price",
+		"images", "image_url", "currency", "location_path",
+	} {
+		t.Run(field, func(t *testing.T) {
+			// Rejected before any query runs
+			_, err := UpdateProductByUser(id, userID, map[string]interface{}{"name": "Rice", field: "x"})
+			if !errors.Is(err, ErrFieldNotEditable) {
+				t.Fatalf("err = %v, want %v", err, ErrFieldNotEditable)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Reputation tuning
+const (
+	neutralReputation    = 0.5
+	moderatorPenaltyRate = 0.8  // each moderator penalty keeps 80% of the score
+	maxConsensusError    = 10.0 // caps the effect of one wild report
+	minReportWeight      = 0.1
+)
+
+// ReputationEvent is an incremental change to a contributor's record
+type ReputationEvent struct {
+	Confirmations      int
+	Disputes           int
+	ModeratorPenalties int
+	// ConsensusError is the robust z-score of one report against the
+	// recent consensus; nil when the event carries no price comparison.
+	ConsensusError *float64
+}
+
+// ComputeReputation derives a [0,1] score from a contributor's counters.
+// Votes and closeness to consensus count equally, each starting from a
+// neutral 0.5, and every moderator penalty scales the result down.
+func ComputeReputation(r model.Reputation) float64 {
+	votes := float64(r.Confirmations+1) / float64(r.Confirmations+r.Disputes+2)
+
+	consensus := neutralReputation
+	if r.ConsensusReports > 0 {
+		meanError := r.ConsensusErrorSum / float64(r.ConsensusReports)
+		consensus = math.Exp(-meanError / config.OutlierThreshold)
+	}
+
+	score := (votes + consensus) / 2 * math.Pow(moderatorPenaltyRate, float64(r.ModeratorPenalties))
+	return math.Round(score*1000) / 1000
+}
+
+// reputationScore treats contributors without any events as neutral
+func reputationScore(r model.Reputation) float64 {
+	if r.UpdatedAt.IsZero() {
+		return neutralReputation
+	}
+	return r.Score
+}
+
+// userReputation loads a contributor's current score
+func userReputation(ctx context.Context, userID primitive.ObjectID) float64 {
+	var user model.User
+	err := userCollection.FindOne(ctx, bson.M{"_id": userID}, options.FindOne().SetProjection(bson.M{"reputation": 1})).Decode(&user)
+	if err != nil {
+		return neutralReputation
+	}
+	return reputationScore(user.Reputation)
+}
+
+// reportWeight is how much a contributor's reports count in aggregates
+func reportWeight(reputation float64) float64 {
+	return math.Max(reputation, minReportWeight)
+}
+
+// ✅ Apply an event to a contributor's counters and recompute the score
+func ApplyReputationEvent(ctx context.Context, userID primitive.ObjectID, event ReputationEvent) error {
+	if userID.IsZero() {
+		return nil
+	}
+
+	inc := bson.M{
+		"reputation.confirmations":      event.Confirmations,
+		"reputation.disputes":           event.Disputes,
+		"reputation.moderatorPenalties": event.ModeratorPenalties,
+	}
+	if event.ConsensusError != nil {
+		inc["reputation.consensusReports"] = 1
+		inc["reputation.consensusErrorSum"] = math.Min(*event.ConsensusError, maxConsensusError)
+	}
+
+	var user model.User
+	err := userCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": userID},
+		bson.M{"$inc": inc},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if err != nil {
+		return err
+	}
+
+	// Derived from the counters just written, so concurrent events converge
+	_, err = userCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{"$set": bson.M{
+		"reputation.score":     ComputeReputation(user.Reputation),
+		"reputation.updatedAt": time.Now(),
+	}})
+	return err
+}
+
+// applyReputationEventAsync records an event without failing the request
+func applyReputationEventAsync(userID primitive.ObjectID, event ReputationEvent) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := ApplyReputationEvent(ctx, userID, event); err != nil {
+			log.Printf("⚠️ Failed to update reputation for %s: %v", userID.Hex(), err)
+		}
+	}()
+}
+
+// voteEvent is the reputation change when a vote moves from previous to next
+// ("" meaning no vote).
+func voteEvent(previous, next string) ReputationEvent {
+	var event ReputationEvent
+	delta := func(vote string, n int) {
+		switch vote {
+		case model.VoteConfirm:
+			event.Confirmations += n
+		case model.VoteDispute:
+			event.Disputes += n
+		}
+	}
+	delta(previous, -1)
+	delta(next, 1)
+	return event
+}
+
+type PublicProfile struct {
+	ID         primitive.ObjectID `json:"id"`
+	Name       string             `json:"name"`
+	Reputation model.Reputation   `json:"reputation"`
+	Reports    int64              `json:"reports"`
+	JoinedAt   time.Time          `json:"joined_at"`
+}
+
+// 👤 Public profile with reputation
+func GetPublicProfile(id string) (*PublicProfile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := toObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var user model.User
+	if err := userCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	reports, err := productCollection.CountDocuments(ctx, bson.M{"user_id": objID, "deleted_at": nil})
+	if err != nil {
+		return nil, err
+	}
+
+	user.Reputation.Score = reputationScore(user.Reputation)
+	return &PublicProfile{
+		ID:         user.ID,
+		Name:       user.Name,
+		Reputation: user.Reputation,
+		Reports:    reports,
+		JoinedAt:   user.CreatedAt,
+	}, nil
+}
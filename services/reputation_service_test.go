@@ -0,0 +1,96 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+)
+
+func TestComputeReputation(t *testing.T) {
+	saved := config.OutlierThreshold
+	config.OutlierThreshold = 3.5
+	t.Cleanup(func() { config.OutlierThreshold = saved })
+
+	tests := []struct {
+		name       string
+		reputation model.Reputation
+		want       float64
+	}{
+		{"new user", model.Reputation{}, 0.5},
+		{"confirmed", model.Reputation{Confirmations: 8}, 0.7},
+		{"disputed", model.Reputation{Disputes: 8}, 0.3},
+		{"on consensus", model.Reputation{ConsensusReports: 5}, 0.75},
+		{
+			// Every report capped at the maximum error: exp(-10/3.5) ≈ 0.057
+			"all outliers",
+			model.Reputation{ConsensusReports: 4, ConsensusErrorSum: 4 * maxConsensusError},
+			0.279,
+		},
+		{"two moderator penalties", model.Reputation{ModeratorPenalties: 2}, 0.32},
+		{
+			"worst case",
+			model.Reputation{Disputes: 1000, ConsensusReports: 10, ConsensusErrorSum: 10 * maxConsensusError, ModeratorPenalties: 20},
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeReputation(tt.reputation)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ComputeReputation = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeReputationStaysInRange(t *testing.T) {
+	saved := config.OutlierThreshold
+	config.OutlierThreshold = 3.5
+	t.Cleanup(func() { config.OutlierThreshold = saved })
+
+	for confirmations := 0; confirmations < 20; confirmations += 3 {
+		for disputes := 0; disputes < 20; disputes += 3 {
+			for reports := 0; reports < 5; reports++ {
+				r := model.Reputation{
+					Confirmations:     confirmations,
+					Disputes:          disputes,
+					ConsensusReports:  reports,
+					ConsensusErrorSum: float64(reports) * 2.5,
+				}
+				if score := ComputeReputation(r); !(score >= 0 && score <= 1) {
+					t.Fatalf("ComputeReputation(%+v) = %g, outside [0,1]", r, score)
+				}
+			}
+		}
+	}
+}
+
+func TestVoteEvent(t *testing.T) {
+	tests := []struct {
+		name           string
+		previous, next string
+		want           ReputationEvent
+	}{
+		{"first confirm", "", model.VoteConfirm, ReputationEvent{Confirmations: 1}},
+		{"first dispute", "", model.VoteDispute, ReputationEvent{Disputes: 1}},
+		{"switch to dispute", model.VoteConfirm, model.VoteDispute, ReputationEvent{Confirmations: -1, Disputes: 1}},
+		{"switch to confirm", model.VoteDispute, model.VoteConfirm, ReputationEvent{Confirmations: 1, Disputes: -1}},
+		{"withdraw confirm", model.VoteConfirm, "", ReputationEvent{Confirmations: -1}},
+		{"withdraw dispute", model.VoteDispute, "", ReputationEvent{Disputes: -1}},
+		{"same vote again", model.VoteConfirm, model.VoteConfirm, ReputationEvent{}},
+		{"no vote", "", "", ReputationEvent{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := voteEvent(tt.previous, tt.next)
+			if got.Confirmations != tt.want.Confirmations || got.Disputes != tt.want.Disputes ||
+				got.ModeratorPenalties != 0 || got.ConsensusError != nil {
+				t.Errorf("voteEvent(%q, %q) = %+v, want %+v", tt.previous, tt.next, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var revisionCollection *mongo.Collection
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
+)
+
+func InitRevisionService() {
+	revisionCollection = config.DB.Collection("product_revisions")
+	ensureIndexes(revisionCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+}
+
+// bsonValue encodes v the way it would be stored in a document
+func bsonValue(v interface{}) (bson.RawValue, error) {
+	raw, err := bson.Marshal(bson.M{"v": v})
+	if err != nil {
+		return bson.RawValue{}, err
+	}
+	return bson.Raw(raw).LookupErr("v")
+}
+
+// decodeLike decodes a stored value into the Go type of like, so old and new
+// values of a change render the same way
+func decodeLike(raw bson.RawValue, like interface{}) interface{} {
+	if like != nil {
+		target := reflect.New(reflect.TypeOf(like))
+		if err := raw.Unmarshal(target.Interface()); err == nil {
+			return target.Elem().Interface()
+		}
+	}
+	var v interface{}
+	if err := raw.Unmarshal(&v); err != nil {
+		return nil
+	}
+	return v
+}
+
+// diffFields lists the fields in update whose stored value in before differs.
+// Values are compared by their BSON encoding so a value read back from Mongo
+// matches the same value about to be written.
+func diffFields(before bson.Raw, update map[string]interface{}) []model.FieldChange {
+	keys := make([]string, 0, len(update))
+	for key := range update {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var changes []model.FieldChange
+	for _, key := range keys {
+		next, err := bsonValue(update[key])
+		if err != nil {
+			continue
+		}
+		change := model.FieldChange{Field: key, New: update[key]}
+		if old, err := before.LookupErr(key); err == nil {
+			if old.Type == next.Type && bytes.Equal(old.Value, next.Value) {
+				continue
+			}
+			change.Old = decodeLike(old, update[key])
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// diffUnset lists the fields in unset that before still holds
+func diffUnset(before bson.Raw, unset bson.M) []model.FieldChange {
+	keys := make([]string, 0, len(unset))
+	for key := range unset {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var changes []model.FieldChange
+	for _, key := range keys {
+		old, err := before.LookupErr(key)
+		if err != nil || old.Type == bson.TypeNull {
+			continue
+		}
+		changes = append(changes, model.FieldChange{Field: key, Old: decodeLike(old, nil)})
+	}
+	return changes
+}
+
+// recordRevision appends to a product's history. Failures are logged rather
+// than failing the change they describe.
+func recordRevision(ctx context.Context, productID, actorID primitive.ObjectID, action string, changes []model.FieldChange) {
+	revision := model.ProductRevision{
+		ProductID: productID,
+		ActorID:   &actorID,
+		Action:    action,
+		Changes:   changes,
+		CreatedAt: time.Now(),
+	}
+	if _, err := revisionCollection.InsertOne(ctx, revision); err != nil {
+		log.Printf("⚠️ Failed to record %s revision for product %s: %v", action, productID.Hex(), err)
+	}
+}
+
+// 📜 A product's revisions, newest first. Unless showActors is set, the
+// actor of a change the owner did not make is left out, so the public
+// history does not name the moderator behind a hide or removal.
+func GetProductHistory(product *model.Product, limit int, showActors bool) ([]model.ProductRevision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+	cursor, err := revisionCollection.Find(ctx, bson.M{"product_id": product.ID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revisions := []model.ProductRevision{}
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	if !showActors {
+		redactActors(revisions, product.UserID)
+	}
+	return revisions, nil
+}
+
+// redactActors drops the actor of every revision not made by owner
+func redactActors(revisions []model.ProductRevision, owner primitive.ObjectID) {
+	for i := range revisions {
+		if actor := revisions[i].ActorID; actor != nil && *actor != owner {
+			revisions[i].ActorID = nil
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func mustMarshal(t *testing.T, doc interface{}) bson.Raw {
+	t.Helper()
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestDiffFields(t *testing.T) {
+	saved := config.DefaultCurrency
+	config.DefaultCurrency = "NGN"
+	t.Cleanup(func() { config.DefaultCurrency = saved })
+
+	price := model.Money{Amount: 50000, Currency: "NGN"}
+	before := mustMarshal(t, bson.M{
+		"name":         "Rice",
+		"price":        price,
+		"legacy_price": 250.5,
+		"quantity":     2.0,
+	})
+
+	tests := []struct {
+		name   string
+		update map[string]interface{}
+		want   []model.FieldChange
+	}{
+		{"unchanged", map[string]interface{}{"name": "Rice", "price": price, "quantity": 2.0}, nil},
+		{
+			"changed string",
+			map[string]interface{}{"name": "Ofada rice"},
+			[]model.FieldChange{{Field: "name", Old: "Rice", New: "Ofada rice"}},
+		},
+		{
+			"nested money amount",
+			map[string]interface{}{"price": model.Money{Amount: 55000, Currency: "NGN"}},
+			[]model.FieldChange{{Field: "price", Old: price, New: model.Money{Amount: 55000, Currency: "NGN"}}},
+		},
+		{
+			"nested money currency",
+			map[string]interface{}{"price": model.Money{Amount: 50000, Currency: "GHS"}},
+			[]model.FieldChange{{Field: "price", Old: price, New: model.Money{Amount: 50000, Currency: "GHS"}}},
+		},
+		{
+			// A legacy number reads back as Money in the default currency
+			"legacy number replaced by money",
+			map[string]interface{}{"legacy_price": model.Money{Amount: 25050, Currency: "NGN"}},
+			[]model.FieldChange{{Field: "legacy_price", Old: model.Money{Amount: 25050, Currency: "NGN"}, New: model.Money{Amount: 25050, Currency: "NGN"}}},
+		},
+		{
+			"field that was unset",
+			map[string]interface{}{"unit": "kg"},
+			[]model.FieldChange{{Field: "unit", Old: nil, New: "kg"}},
+		},
+		{
+			"sorted by field",
+			map[string]interface{}{"unit": "kg", "name": "Beans", "price": price},
+			[]model.FieldChange{{Field: "name", Old: "Rice", New: "Beans"}, {Field: "unit", Old: nil, New: "kg"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertChanges(t, diffFields(before, tt.update), tt.want)
+		})
+	}
+}
+
+func TestDiffUnset(t *testing.T) {
+	locationID := primitive.NewObjectID()
+	before := mustMarshal(t, bson.M{
+		"location_id":   locationID,
+		"location_path": nil,
+		"area":          "Yaba",
+	})
+
+	changes := diffUnset(before, bson.M{"location_id": "", "location_path": "", "market_id": ""})
+	assertChanges(t, changes, []model.FieldChange{{Field: "location_id", Old: locationID}})
+}
+
+func assertChanges(t *testing.T, got, want []model.FieldChange) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("changes = %+v, want %+v", got, want)
+	}
+	for i := range got {
+		if got[i].Field != want[i].Field || got[i].Old != want[i].Old || got[i].New != want[i].New {
+			t.Errorf("change %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestImageChanges(t *testing.T) {
+	created := time.Now().Truncate(time.Millisecond)
+	a := model.ProductImage{ID: primitive.NewObjectID(), URL: "https://img.example/a.jpg", Position: 0, Primary: true, CreatedAt: created}
+	b := model.ProductImage{ID: primitive.NewObjectID(), URL: "https://img.example/b.jpg", Position: 1, CreatedAt: created}
+	product := model.Product{ID: primitive.NewObjectID(), ImageURL: a.URL, Images: []model.ProductImage{a, b}}
+
+	if changes := imageChanges(product, []model.ProductImage{a, b}); len(changes) != 0 {
+		t.Errorf("unchanged images recorded %+v", changes)
+	}
+
+	b2, a2 := b, a
+	b2.Position, b2.Primary, a2.Position, a2.Primary = 0, true, 1, false
+	changes := imageChanges(product, []model.ProductImage{b2, a2})
+	if len(changes) != 2 || changes[0].Field != "image_url" || changes[0].Old != a.URL || changes[0].New != b.URL || changes[1].Field != "images" {
+		t.Errorf("reorder recorded %+v, want image_url and images", changes)
+	}
+
+	legacy := model.Product{ID: primitive.NewObjectID(), ImageURL: a.URL}
+	changes = imageChanges(legacy, []model.ProductImage{a})
+	if len(changes) != 1 || changes[0].Field != "images" || changes[0].Old != nil {
+		t.Errorf("first image list on a legacy product recorded %+v", changes)
+	}
+}
+
+func TestRedactActors(t *testing.T) {
+	owner, moderator := primitive.NewObjectID(), primitive.NewObjectID()
+	revisions := []model.ProductRevision{
+		{Action: model.RevisionCreate, ActorID: &owner},
+		{Action: model.RevisionModerate, ActorID: &moderator},
+		{Action: model.RevisionDelete, ActorID: &moderator},
+		{Action: model.RevisionUpdate},
+	}
+
+	redactActors(revisions, owner)
+	if revisions[0].ActorID == nil || *revisions[0].ActorID != owner {
+		t.Errorf("owner's revision lost its actor")
+	}
+	for _, revision := range revisions[1:] {
+		if revision.ActorID != nil {
+			t.Errorf("%s revision still names actor %s", revision.Action, revision.ActorID.Hex())
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const sortRelevance = "relevance"
+
+var ErrEmptySearch = errors.New("search query is required")
+
+// Field weights shared by the Mongo text index and the in-memory searcher
+var searchWeights = map[string]int{
+	"name":        10,
+	"category":    5,
+	"description": 1,
+}
+
+type SearchQuery struct {
+	Text   string
+	Filter ProductFilter
+	Limit  int
+	Cursor string
+}
+
+type SearchHit struct {
+	Product      model.Product     `json:"product"`
+	Score        float64           `json:"score"`
+	MatchedTerms []string          `json:"matched_terms"`
+	Highlights   map[string]string `json:"highlights"`
+}
+
+type SearchPage struct {
+	Hits       []SearchHit `json:"hits"`
+	NextCursor string      `json:"next_cursor"`
+}
+
+// ProductSearcher ranks products against a free-text query
+type ProductSearcher interface {
+	Search(ctx context.Context, q SearchQuery) (*SearchPage, error)
+}
+
+// ProductSearch is the searcher used by the HTTP layer
+var ProductSearch ProductSearcher
+
+// normalizeSearchQuery applies defaults shared by every searcher
+func normalizeSearchQuery(q *SearchQuery) error {
+	q.Text = strings.TrimSpace(q.Text)
+	if q.Text == "" {
+		return ErrEmptySearch
+	}
+	if err := q.Filter.Validate(); err != nil {
+		return &FilterError{Err: err}
+	}
+	if q.Limit <= 0 {
+		q.Limit = config.ProductPageSize
+	}
+	if q.Limit > config.MaxProductPageSize {
+		q.Limit = config.MaxProductPageSize
+	}
+	return nil
+}
+
+func decodeSearchCursor(token string) (*listCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	cur, err := decodeCursor(token)
+	if err != nil || cur.Sort != sortRelevance {
+		return nil, ErrInvalidCursor
+	}
+	return &cur, nil
+}
+
+// finishSearchPage trims the look-ahead hit and adds highlights
+func finishSearchPage(text string, hits []SearchHit, limit int) *SearchPage {
+	page := &SearchPage{Hits: hits}
+	if len(hits) > limit {
+		page.Hits = hits[:limit]
+		last := page.Hits[len(page.Hits)-1]
+		page.NextCursor = encodeCursor(listCursor{Sort: sortRelevance, Num: last.Score, ID: last.Product.ID})
+	}
+
+	terms := searchTerms(text)
+	for i := range page.Hits {
+		page.Hits[i].MatchedTerms, page.Hits[i].Highlights = highlightProduct(terms, page.Hits[i].Product)
+	}
+	return page
+}
+
+// --- Mongo text index implementation ---
+
+type mongoProductSearcher struct {
+	coll *mongo.Collection
+}
+
+func NewMongoProductSearcher(coll *mongo.Collection) ProductSearcher {
+	return &mongoProductSearcher{coll: coll}
+}
+
+func (s *mongoProductSearcher) Search(ctx context.Context, q SearchQuery) (*SearchPage, error) {
+	if err := normalizeSearchQuery(&q); err != nil {
+		return nil, err
+	}
+	cur, err := decodeSearchCursor(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	match := q.Filter.toBSON()
+	match["$text"] = bson.M{"$search": q.Text}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}},
+	}
+	if cur != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: afterCursor(sortSpec{field: "score", direction: -1}, *cur)}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: -1}}}},
+		bson.D{{Key: "$limit", Value: q.Limit + 1}},
+	)
+
+	cursor, err := s.coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var hits []SearchHit
+	for cursor.Next(ctx) {
+		var doc struct {
+			model.Product `bson:",inline"`
+			Score         float64 `bson:"score"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		hits = append(hits, SearchHit{Product: doc.Product, Score: doc.Score})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return finishSearchPage(q.Text, hits, q.Limit), nil
+}
+
+// --- In-memory implementation ---
+
+// MemoryProductSearcher scores an in-memory product slice with the same
+// field weights as the text index. It backs tests and local tooling.
+type MemoryProductSearcher struct {
+	Products []model.Product
+}
+
+func (s *MemoryProductSearcher) Search(ctx context.Context, q SearchQuery) (*SearchPage, error) {
+	if err := normalizeSearchQuery(&q); err != nil {
+		return nil, err
+	}
+	cur, err := decodeSearchCursor(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := searchTerms(q.Text)
+	var hits []SearchHit
+	for _, p := range s.Products {
+		if !q.Filter.Matches(p) {
+			continue
+		}
+		score := memoryScore(terms, p)
+		if score == 0 {
+			continue
+		}
+		hits = append(hits, SearchHit{Product: p, Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Product.ID.Hex() > hits[j].Product.ID.Hex()
+	})
+
+	if cur != nil {
+		start := len(hits)
+		for i, h := range hits {
+			if h.Score < cur.Num || (h.Score == cur.Num && h.Product.ID.Hex() < cur.ID.Hex()) {
+				start = i
+				break
+			}
+		}
+		hits = hits[start:]
+	}
+	if len(hits) > q.Limit+1 {
+		hits = hits[:q.Limit+1]
+	}
+
+	return finishSearchPage(q.Text, hits, q.Limit), nil
+}
+
+func memoryScore(terms []string, p model.Product) float64 {
+	fields := map[string]string{"name": p.Name, "category": p.Category, "description": p.Description}
+	score := 0.0
+	for field, text := range fields {
+		for _, word := range searchTerms(text) {
+			for _, term := range terms {
+				if termMatches(term, word) {
+					score += float64(searchWeights[field])
+				}
+			}
+		}
+	}
+	return score
+}
+
+// --- Highlighting ---
+
+// searchTerms lower-cases and splits text into word tokens
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// termMatches approximates the text index stemming: "tomato" matches
+// "tomatoes" and vice versa.
+func termMatches(term, word string) bool {
+	if term == word {
+		return true
+	}
+	if len(term) < 3 || len(word) < 3 {
+		return false
+	}
+	return strings.HasPrefix(word, term) || strings.HasPrefix(term, word)
+}
+
+// highlightProduct wraps matched words in <mark> tags per field. The
+// highlights are HTML: safe to render, with the listing text escaped.
+func highlightProduct(terms []string, p model.Product) ([]string, map[string]string) {
+	fields := map[string]string{"name": p.Name, "category": p.Category, "description": p.Description}
+	highlights := map[string]string{}
+	matched := map[string]bool{}
+
+	for field, text := range fields {
+		marked, found := highlightText(terms, text, matched)
+		if found {
+			highlights[field] = marked
+		}
+	}
+
+	matchedTerms := make([]string, 0, len(matched))
+	for _, term := range terms {
+		if matched[term] {
+			matchedTerms = append(matchedTerms, term)
+			delete(matched, term)
+		}
+	}
+	return matchedTerms, highlights
+}
+
+// highlightText wraps matched words in <mark> tags. Everything else is
+// HTML-escaped, so listing text can never inject markup into the result.
+func highlightText(terms []string, text string, matched map[string]bool) (string, bool) {
+	var b strings.Builder
+	found := false
+	runes := []rune(text)
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }
+
+	for i := 0; i < len(runes); {
+		j := i
+		if !isWord(runes[i]) {
+			for j < len(runes) && !isWord(runes[j]) {
+				j++
+			}
+			b.WriteString(html.EscapeString(string(runes[i:j])))
+			i = j
+			continue
+		}
+		for j < len(runes) && isWord(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		hit := false
+		for _, term := range terms {
+			if termMatches(term, strings.ToLower(word)) {
+				matched[term] = true
+				hit = true
+			}
+		}
+		if hit {
+			found = true
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		i = j
+	}
+	return b.String(), found
+}
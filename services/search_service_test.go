@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testProductID gives fixture products stable, ordered IDs
+func testProductID(n byte) primitive.ObjectID {
+	var id primitive.ObjectID
+	id[11] = n
+	return id
+}
+
+func searchFixture() []model.Product {
+	now := time.Now()
+	deleted := now.Add(-time.Hour)
+	product := func(n byte, name, category, description, area string) model.Product {
+		return model.Product{
+			ID:          testProductID(n),
+			Name:        name,
+			Category:    category,
+			Description: description,
+			Area:        area,
+			Price:       model.Money{Amount: int64(n) * 10000, Currency: "NGN"},
+			Status:      model.ReportStatusPublished,
+			CreatedAt:   &now,
+		}
+	}
+
+	products := []model.Product{
+		product(1, "Fresh tomatoes", "vegetables", "Ripe and red", "Yaba"),
+		product(2, "Pepper", "vegetables", "Goes well with tomato stew", "Yaba"),
+		product(3, "Tomato paste", "groceries", "Tinned tomato paste", "Ikeja"),
+		product(4, "Rice", "grains", "Long grain rice", "Yaba"),
+		product(5, "Tomato <script>alert(1)</script>", "vegetables", "Fish & chips", "Ikeja"),
+		product(6, "Tomato seedlings", "vegetables", "Held for review", "Yaba"),
+		product(7, "Tomato crate", "vegetables", "Deleted listing", "Yaba"),
+	}
+	products[5].Status = model.ReportStatusPending
+	products[6].DeletedAt = &deleted
+	return products
+}
+
+func hitIDs(hits []SearchHit) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.Product.ID
+	}
+	return ids
+}
+
+func TestMemoryProductSearcherRanking(t *testing.T) {
+	searcher := &MemoryProductSearcher{Products: searchFixture()}
+
+	tests := []struct {
+		name string
+		text string
+		want []primitive.ObjectID
+	}{
+		// Name matches (weight 10) beat description-only matches (weight 1);
+		// a match in name and description scores highest
+		{"name beats description", "tomato", []primitive.ObjectID{testProductID(3), testProductID(5), testProductID(1), testProductID(2)}},
+		{"category match", "grains", []primitive.ObjectID{testProductID(4)}},
+		{"case insensitive", "RICE", []primitive.ObjectID{testProductID(4)}},
+		{"no match", "yam", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := searcher.Search(context.Background(), SearchQuery{Text: tt.text, Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hitIDs(page.Hits); len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("hits = %v, want %v", got, tt.want)
+			}
+			for i := 1; i < len(page.Hits); i++ {
+				if page.Hits[i].Score > page.Hits[i-1].Score {
+					t.Errorf("hit %d scores %g above hit %d (%g)", i, page.Hits[i].Score, i-1, page.Hits[i-1].Score)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryProductSearcherHighlights(t *testing.T) {
+	searcher := &MemoryProductSearcher{Products: searchFixture()}
+
+	tests := []struct {
+		name       string
+		text       string
+		id         primitive.ObjectID
+		matched    []string
+		highlights map[string]string
+	}{
+		{
+			name:       "stemmed match in name",
+			text:       "tomato",
+			id:         testProductID(1),
+			matched:    []string{"tomato"},
+			highlights: map[string]string{"name": "Fresh <mark>tomatoes</mark>"},
+		},
+		{
+			name:    "several fields",
+			text:    "tomato paste",
+			id:      testProductID(3),
+			matched: []string{"tomato", "paste"},
+			highlights: map[string]string{
+				"name":        "<mark>Tomato</mark> <mark>paste</mark>",
+				"description": "Tinned <mark>tomato</mark> <mark>paste</mark>",
+			},
+		},
+		{
+			name:    "listing text is escaped",
+			text:    "tomato fish",
+			id:      testProductID(5),
+			matched: []string{"tomato", "fish"},
+			highlights: map[string]string{
+				"name":        "<mark>Tomato</mark> &lt;script&gt;alert(1)&lt;/script&gt;",
+				"description": "<mark>Fish</mark> &amp; chips",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := searcher.Search(context.Background(), SearchQuery{Text: tt.text, Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, hit := range page.Hits {
+				if hit.Product.ID != tt.id {
+					continue
+				}
+				if !reflect.DeepEqual(hit.MatchedTerms, tt.matched) {
+					t.Errorf("matched terms = %v, want %v", hit.MatchedTerms, tt.matched)
+				}
+				if !reflect.DeepEqual(hit.Highlights, tt.highlights) {
+					t.Errorf("highlights = %v, want %v", hit.Highlights, tt.highlights)
+				}
+				return
+			}
+			t.Fatalf("product %s not in hits", tt.id.Hex())
+		})
+	}
+}
+
+func TestMemoryProductSearcherFilters(t *testing.T) {
+	searcher := &MemoryProductSearcher{Products: searchFixture()}
+	maxPrice := model.Money{Amount: 30000, Currency: "NGN"}
+
+	tests := []struct {
+		name   string
+		filter ProductFilter
+		want   []primitive.ObjectID
+	}{
+		{"area", ProductFilter{Area: "Ikeja"}, []primitive.ObjectID{testProductID(3), testProductID(5)}},
+		{"category", ProductFilter{Categories: []string{"vegetables"}}, []primitive.ObjectID{testProductID(5), testProductID(1), testProductID(2)}},
+		{"price", ProductFilter{Currency: "NGN", MaxPrice: &maxPrice}, []primitive.ObjectID{testProductID(3), testProductID(1), testProductID(2)}},
+		{"other currency", ProductFilter{Currency: "GHS"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := searcher.Search(context.Background(), SearchQuery{Text: "tomato", Filter: tt.filter, Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hitIDs(page.Hits); len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("hits = %v, want %v", got, tt.want)
+			}
+			for _, hit := range page.Hits {
+				if hit.Product.Status == model.ReportStatusPending || hit.Product.DeletedAt != nil {
+					t.Errorf("hidden product %s returned", hit.Product.ID.Hex())
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryProductSearcherCursor(t *testing.T) {
+	var products []model.Product
+	now := time.Now()
+	for n := byte(1); n <= 7; n++ {
+		name := "Tomato"
+		if n%2 == 0 {
+			// Ties on score are broken by ID
+			name = "Tomato tomato"
+		}
+		products = append(products, model.Product{ID: testProductID(n), Name: name, Status: model.ReportStatusPublished, CreatedAt: &now})
+	}
+	searcher := &MemoryProductSearcher{Products: products}
+
+	var seen []primitive.ObjectID
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("cursor does not terminate")
+		}
+		page, err := searcher.Search(context.Background(), SearchQuery{Text: "tomato", Limit: 3, Cursor: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Hits) > 3 {
+			t.Fatalf("page has %d hits, limit is 3", len(page.Hits))
+		}
+		seen = append(seen, hitIDs(page.Hits)...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	want := []primitive.ObjectID{
+		testProductID(6), testProductID(4), testProductID(2),
+		testProductID(7), testProductID(5), testProductID(3), testProductID(1),
+	}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("pages = %v, want %v", seen, want)
+	}
+}
+
+func TestMemoryProductSearcherErrors(t *testing.T) {
+	searcher := &MemoryProductSearcher{Products: searchFixture()}
+
+	if _, err := searcher.Search(context.Background(), SearchQuery{Text: "  "}); !errors.Is(err, ErrEmptySearch) {
+		t.Errorf("empty query error = %v, want %v", err, ErrEmptySearch)
+	}
+	if _, err := searcher.Search(context.Background(), SearchQuery{Text: "tomato", Cursor: "garbage"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("bad cursor error = %v, want %v", err, ErrInvalidCursor)
+	}
+	listCursorToken := encodeCursor(listCursor{Sort: "newest", ID: testProductID(1)})
+	if _, err := searcher.Search(context.Background(), SearchQuery{Text: "tomato", Cursor: listCursorToken}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("listing cursor error = %v, want %v", err, ErrInvalidCursor)
+	}
+}
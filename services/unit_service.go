@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var unitCollection *mongo.Collection
+
+var (
+	ErrUnknownUnit     = errors.New("unknown unit")
+	ErrInvalidQuantity = errors.New("quantity must be a positive number")
+)
+
+// ValidateQuantity accepts finite positive quantities only
+func ValidateQuantity(quantity float64) error {
+	if math.IsNaN(quantity) || math.IsInf(quantity, 0) || quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	return nil
+}
+
+// ParseQuantity reads a quantity as typed, e.g. "2" or "0.5"
+func ParseQuantity(raw string) (float64, error) {
+	quantity, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return 0, ErrInvalidQuantity
+	}
+	return quantity, ValidateQuantity(quantity)
+}
+
+// Units every deployment understands. Admins add local measures on top.
+var builtinUnits = []model.Unit{
+	{Code: "kg", Name: "kilogram", Dimension: model.DimensionMass, Factor: 1, Aliases: []string{"kilogram", "kilograms", "kgs", "kilo", "kilos"}},
+	{Code: "g", Name: "gram", Dimension: model.DimensionMass, Factor: 0.001, Aliases: []string{"gram", "grams", "gm", "gms"}},
+	{Code: "lb", Name: "pound", Dimension: model.DimensionMass, Factor: 0.45359237, Aliases: []string{"pound", "pounds", "lbs"}},
+	{Code: "tonne", Name: "tonne", Dimension: model.DimensionMass, Factor: 1000, Aliases: []string{"t", "ton", "tons", "tonnes"}},
+	{Code: "l", Name: "litre", Dimension: model.DimensionVolume, Factor: 1, Aliases: []string{"litre", "litres", "liter", "liters", "ltr"}},
+	{Code: "ml", Name: "millilitre", Dimension: model.DimensionVolume, Factor: 0.001, Aliases: []string{"millilitre", "millilitres", "milliliter", "milliliters", "mls"}},
+	{Code: "cl", Name: "centilitre", Dimension: model.DimensionVolume, Factor: 0.01, Aliases: []string{"centilitre", "centiliter"}},
+	{Code: "gal", Name: "gallon", Dimension: model.DimensionVolume, Factor: 3.785411784, Aliases: []string{"gallon", "gallons"}},
+	{Code: "piece", Name: "piece", Dimension: model.DimensionCount, Factor: 1, Aliases: []string{"pc", "pcs", "pieces", "each", "unit", "units"}},
+	{Code: "pair", Name: "pair", Dimension: model.DimensionCount, Factor: 2, Aliases: []string{"pairs"}},
+	{Code: "dozen", Name: "dozen", Dimension: model.DimensionCount, Factor: 12, Aliases: []string{"doz", "dozens"}},
+	{Code: "paint_bucket", Name: "paint bucket", Dimension: model.DimensionCustom, Factor: 1, Aliases: []string{"paint bucket", "bucket"}},
+	{Code: "derica", Name: "derica", Dimension: model.DimensionCustom, Factor: 1, Aliases: []string{"derica cup"}},
+	{Code: "mudu", Name: "mudu", Dimension: model.DimensionCustom, Factor: 1},
+}
+
+// unitRegistry resolves unit codes and aliases. It is read on every product
+// write, so lookups go through an in-memory index refreshed on admin edits.
+type unitRegistry struct {
+	mu      sync.RWMutex
+	units   map[string]model.Unit
+	aliases map[string]string
+}
+
+var units = newUnitRegistry(nil)
+
+func newUnitRegistry(custom []model.Unit) *unitRegistry {
+	r := &unitRegistry{}
+	r.load(custom)
+	return r
+}
+
+// unitKey folds spacing, case and separators: "Paint-Bucket" -> "paint_bucket"
+func unitKey(raw string) string {
+	fields := strings.FieldsFunc(strings.ToLower(raw), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '.'
+	})
+	return strings.Join(fields, "_")
+}
+
+func (r *unitRegistry) load(custom []model.Unit) {
+	all := map[string]model.Unit{}
+	aliases := map[string]string{}
+	add := func(u model.Unit) {
+		all[u.Code] = u
+		aliases[unitKey(u.Code)] = u.Code
+		aliases[unitKey(u.Name)] = u.Code
+		for _, alias := range u.Aliases {
+			aliases[unitKey(alias)] = u.Code
+		}
+	}
+	for _, u := range custom {
+		add(u)
+	}
+	// Builtins win on conflicting codes or aliases
+	for _, u := range builtinUnits {
+		u.Builtin = true
+		add(u)
+	}
+
+	r.mu.Lock()
+	r.units, r.aliases = all, aliases
+	r.mu.Unlock()
+}
+
+func (r *unitRegistry) lookup(raw string) (model.Unit, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	code, ok := r.aliases[unitKey(raw)]
+	if !ok {
+		return model.Unit{}, false
+	}
+	return r.units[code], true
+}
+
+func (r *unitRegistry) list() []model.Unit {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]model.Unit, 0, len(r.units))
+	for _, u := range r.units {
+		list = append(list, u)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Dimension != list[j].Dimension {
+			return list[i].Dimension < list[j].Dimension
+		}
+		return list[i].Factor < list[j].Factor || (list[i].Factor == list[j].Factor && list[i].Code < list[j].Code)
+	})
+	return list
+}
+
+func InitUnitService() {
+	unitCollection = config.DB.Collection("units")
+	if err := reloadUnits(); err != nil {
+		log.Printf("⚠️ Failed to load custom units, using builtins only: %v", err)
+	}
+}
+
+func reloadUnits() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := unitCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var custom []model.Unit
+	if err := cursor.All(ctx, &custom); err != nil {
+		return err
+	}
+	units.load(custom)
+	return nil
+}
+
+// LookupUnit resolves a unit code, name or alias ("Kilograms", "paint bucket")
+func LookupUnit(raw string) (model.Unit, error) {
+	unit, ok := units.lookup(raw)
+	if !ok {
+		return model.Unit{}, fmt.Errorf("%w: %q", ErrUnknownUnit, raw)
+	}
+	return unit, nil
+}
+
+// 📏 Every unit in the registry, builtins and custom measures
+func ListUnits() []model.Unit {
+	return units.list()
+}
+
+// UnitBasis resolves the unit prices are compared per: a base unit (kg, l,
+// piece) or a custom measure
+func UnitBasis(raw string) (string, error) {
+	unit, err := LookupUnit(raw)
+	if err != nil {
+		return "", err
+	}
+	if base, ok := model.BaseUnits[unit.Dimension]; ok {
+		if unit.Code != base {
+			return "", fmt.Errorf("compare per %s rather than per %s", base, unit.Code)
+		}
+		return base, nil
+	}
+	return unit.Code, nil
+}
+
+// ✅ Register a local measure. A measure with a fixed size can be given a
+// mass or volume dimension and factor so it joins per-kg or per-litre
+// comparisons; otherwise it is compared only with itself.
+func CreateUnit(unit model.Unit) (*model.Unit, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	unit.Code = unitKey(unit.Code)
+	unit.Name = strings.TrimSpace(unit.Name)
+	if unit.Code == "" || unit.Name == "" {
+		return nil, &FilterError{Err: errors.New("code and name are required")}
+	}
+	if _, exists := units.lookup(unit.Code); exists {
+		return nil, &FilterError{Err: fmt.Errorf("unit %q already exists", unit.Code)}
+	}
+	for _, alias := range unit.Aliases {
+		if _, exists := units.lookup(alias); exists {
+			return nil, &FilterError{Err: fmt.Errorf("alias %q is already used", alias)}
+		}
+	}
+
+	switch unit.Dimension {
+	case "", model.DimensionCustom:
+		unit.Dimension, unit.Factor = model.DimensionCustom, 1
+	case model.DimensionMass, model.DimensionVolume, model.DimensionCount:
+		if unit.Factor <= 0 || math.IsNaN(unit.Factor) || math.IsInf(unit.Factor, 0) {
+			return nil, &FilterError{Err: errors.New("factor must be positive for mass, volume and count units")}
+		}
+	default:
+		return nil, &FilterError{Err: errors.New("dimension must be mass, volume, count or custom")}
+	}
+
+	unit.Builtin = false
+	unit.CreatedAt = time.Now()
+	if _, err := unitCollection.InsertOne(ctx, unit); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, &FilterError{Err: fmt.Errorf("unit %q already exists", unit.Code)}
+		}
+		return nil, err
+	}
+	if err := reloadUnits(); err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+// 🗑️ Remove a custom measure. Builtins cannot be deleted, and products keep
+// the unit code and unit price they were saved with.
+func DeleteUnit(code string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	unit, ok := units.lookup(code)
+	if ok && unit.Builtin {
+		return &FilterError{Err: errors.New("builtin units cannot be deleted")}
+	}
+	result, err := unitCollection.DeleteOne(ctx, bson.M{"_id": unitKey(code)})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("unit not found")
+	}
+	return reloadUnits()
+}
+
+// ComputeUnitPrice normalizes a price for quantity of unit to one base unit,
+// rounded to the currency's minor unit. Nil when there is nothing to
+// normalize.
+func ComputeUnitPrice(price model.Money, quantity float64, unit model.Unit) *model.UnitPrice {
+	if ValidateQuantity(quantity) != nil || unit.Factor <= 0 {
+		return nil
+	}
+	per := unit.Code
+	if base, ok := model.BaseUnits[unit.Dimension]; ok {
+		per = base
+	}
+	amount := math.Round(float64(price.Amount) / (quantity * unit.Factor))
+	return &model.UnitPrice{Money: model.Money{Amount: int64(amount), Currency: price.Currency}, Per: per}
+}
+
+// productUnitPrice recomputes a product's unit price from its current fields
+func productUnitPrice(p model.Product) *model.UnitPrice {
+	if p.Unit == "" {
+		return nil
+	}
+	unit, err := LookupUnit(p.Unit)
+	if err != nil {
+		return nil
+	}
+	quantity := p.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	return ComputeUnitPrice(p.Price, quantity, unit)
+}
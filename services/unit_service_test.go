@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"CROWD_MARKET/model"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    float64
+		wantErr bool
+	}{
+		{"2", 2, false},
+		{"0.5", 0.5, false},
+		{" 3 ", 3, false},
+		{"1e3", 1000, false},
+		{"", 0, true},
+		{"abc", 0, true},
+		{"0", 0, true},
+		{"-1", 0, true},
+		{"NaN", 0, true},
+		{"nan", 0, true},
+		{"Inf", 0, true},
+		{"+Inf", 0, true},
+		{"-Infinity", 0, true},
+		{"1e400", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseQuantity(tt.raw)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidQuantity) {
+					t.Fatalf("ParseQuantity(%q) = %g, %v; want %v", tt.raw, got, err, ErrInvalidQuantity)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseQuantity(%q) = %g, %v; want %g", tt.raw, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeUnitPriceRejectsNonFiniteQuantity(t *testing.T) {
+	price := model.Money{Amount: 100000, Currency: "NGN"}
+	kg, err := LookupUnit("kg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, quantity := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), 0, -2} {
+		if got := ComputeUnitPrice(price, quantity, kg); got != nil {
+			t.Errorf("ComputeUnitPrice(%g) = %+v, want nil", quantity, got)
+		}
+	}
+	if got := ComputeUnitPrice(price, 2, kg); got == nil || got.Amount != 50000 || got.Per != "kg" {
+		t.Errorf("ComputeUnitPrice(2) = %+v, want NGN 500 per kg", got)
+	}
+}
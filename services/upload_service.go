@@ -1,49 +1,89 @@
 package services
 
 import (
-	"CROWD_MARKET/config"
 	"context"
-	"mime/multipart"
-	"path/filepath"
-	"strings"
-
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"io"
+	"log"
+	"time"
 )
 
-// ✅ Upload image to Cloudinary
-func UploadToCloudinary(file multipart.File, fileHeader *multipart.FileHeader) (string, error) {
-	ctx := context.Background()
+// StoredImage is an uploaded image and its locally generated variants
+type StoredImage struct {
+	URL          string
+	ThumbnailURL string
+	MediumURL    string
+}
+
+// ✅ Validate, clean and store an uploaded image with its variants. Files
+// are named by content hash, so re-uploading the same picture reuses them;
+// when a write fails, only the files this call added are removed, never
+// ones another product may already point at.
+func UploadImage(r io.Reader) (*StoredImage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
 
-	uploadResult, err := config.Cloud.Upload.Upload(ctx, file, uploader.UploadParams{
-		PublicID: fileHeader.Filename,
-		Folder:   "crowd_market/products",
-	})
+	processed, err := ProcessImage(r)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return uploadResult.SecureURL, nil
+	key := imageFolder + "/" + processed.Hash
+	stored := &StoredImage{}
+	var added []string
+	for _, rendition := range processed.renditions {
+		existed, err := imageStore.Exists(ctx, key+rendition.suffix)
+		if err != nil {
+			deleteImageKeys(ctx, added)
+			return nil, err
+		}
+		url, err := imageStore.Put(ctx, key+rendition.suffix, rendition.ext, rendition.data)
+		if err != nil {
+			deleteImageKeys(ctx, added)
+			return nil, err
+		}
+		if !existed {
+			added = append(added, key+rendition.suffix)
+		}
+		switch rendition.suffix {
+		case "":
+			stored.URL = url
+		case "_thumb":
+			stored.ThumbnailURL = url
+		case "_medium":
+			stored.MediumURL = url
+		}
+	}
+	return stored, nil
 }
 
-// 🧹 Delete an image from Cloudinary using its public ID
-func DeleteFromCloudinary(imageURL string) error {
-	ctx := context.Background()
-
-	// Extract the public ID from the image URL
-	// Example: https://res.cloudinary.com/.../crowd_market/products/image.jpg
-	parts := strings.Split(imageURL, "/")
-	if len(parts) == 0 {
-		return nil
+// deleteImageKeys removes the files a failed upload wrote
+func deleteImageKeys(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := imageStore.Delete(ctx, key); err != nil {
+			log.Printf("⚠️ Failed to clean up partial upload %s: %v", key, err)
+		}
 	}
+}
 
-	publicIDWithExt := parts[len(parts)-1]
-	publicID := strings.TrimSuffix(publicIDWithExt, filepath.Ext(publicIDWithExt))
+// deleteImageKey removes an image and its variants
+func deleteImageKey(ctx context.Context, key string) error {
+	var firstErr error
+	for _, suffix := range []string{"", "_thumb", "_medium"} {
+		if err := imageStore.Delete(ctx, key+suffix); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
 
-	// Prepend folder name
-	fullPublicID := "crowd_market/products/" + publicID
+// 🧹 Delete an image and its variants from the image store by URL
+func DeleteImage(imageURL string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-	_, err := config.Cloud.Upload.Destroy(ctx, uploader.DestroyParams{
-		PublicID: fullPublicID,
-	})
-	return err
+	key, ok := imageKeyFromURL(imageURL)
+	if !ok {
+		return nil
+	}
+	return deleteImageKey(ctx, key)
 }
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memImageStore keeps files in memory; failPut makes Put fail for keys
+// ending in that suffix. Files missing from created were stored long ago.
+type memImageStore struct {
+	files   map[string][]byte
+	created map[string]time.Time
+	failPut string
+}
+
+func newMemImageStore(t *testing.T) *memImageStore {
+	store := &memImageStore{files: map[string][]byte{}, created: map[string]time.Time{}}
+	saved := imageStore
+	imageStore = store
+	t.Cleanup(func() { imageStore = saved })
+	return store
+}
+
+func (s *memImageStore) Put(ctx context.Context, key, ext string, data []byte) (string, error) {
+	if s.failPut != "" && strings.HasSuffix(key, s.failPut) {
+		return "", errors.New("store unavailable")
+	}
+	s.files[key] = data
+	return "https://img.example/" + key + ext, nil
+}
+
+func (s *memImageStore) Delete(ctx context.Context, key string) error {
+	delete(s.files, key)
+	return nil
+}
+
+func (s *memImageStore) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok := s.files[key]
+	return ok, nil
+}
+
+func (s *memImageStore) SignUpload(key string, expires time.Time) (UploadTarget, error) {
+	return UploadTarget{Method: "PUT", URL: "https://img.example/" + key}, nil
+}
+
+func (s *memImageStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.files[key]
+	if !ok {
+		return nil, ErrUploadMissing
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memImageStore) List(ctx context.Context, prefix string) ([]StoredObject, error) {
+	var objects []StoredObject
+	for key := range s.files {
+		if strings.HasPrefix(key, prefix+"/") {
+			objects = append(objects, StoredObject{Key: key, CreatedAt: s.created[key]})
+		}
+	}
+	return objects, nil
+}
+
+func (s *memImageStore) keys() []string {
+	keys := make([]string, 0, len(s.files))
+	for key := range s.files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestUploadImageStoresEveryRendition(t *testing.T) {
+	withMaxImageBytes(t, 1<<20)
+	store := newMemImageStore(t)
+	data := encodeTestPNG(t, testImage(400, 300))
+
+	stored, err := UploadImage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.files) != 3 {
+		t.Fatalf("stored %v, want the original and two variants", store.keys())
+	}
+	if !strings.HasSuffix(stored.URL, ".png") || !strings.Contains(stored.ThumbnailURL, "_thumb") || !strings.Contains(stored.MediumURL, "_medium") {
+		t.Errorf("stored = %+v", stored)
+	}
+}
+
+func TestUploadImageFailureKeepsExistingFiles(t *testing.T) {
+	withMaxImageBytes(t, 1<<20)
+	data := encodeTestPNG(t, testImage(400, 300))
+
+	t.Run("fresh upload is removed", func(t *testing.T) {
+		store := newMemImageStore(t)
+		store.failPut = "_medium"
+		if _, err := UploadImage(bytes.NewReader(data)); err == nil {
+			t.Fatal("UploadImage succeeded with a failing store")
+		}
+		if len(store.files) != 0 {
+			t.Errorf("left %v behind", store.keys())
+		}
+	})
+
+	t.Run("files another product uses survive", func(t *testing.T) {
+		store := newMemImageStore(t)
+		if _, err := UploadImage(bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+		before := store.keys()
+
+		store.failPut = "_medium"
+		if _, err := UploadImage(bytes.NewReader(data)); err == nil {
+			t.Fatal("UploadImage succeeded with a failing store")
+		}
+		if after := store.keys(); strings.Join(after, ",") != strings.Join(before, ",") {
+			t.Errorf("files = %v, want %v", after, before)
+		}
+	})
+
+	t.Run("only the files this call added are removed", func(t *testing.T) {
+		store := newMemImageStore(t)
+		processed, err := ProcessImage(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		shared := imageFolder + "/" + processed.Hash
+		store.files[shared] = []byte("already there")
+
+		store.failPut = "_medium"
+		if _, err := UploadImage(bytes.NewReader(data)); err == nil {
+			t.Fatal("UploadImage succeeded with a failing store")
+		}
+		if got := store.keys(); len(got) != 1 || got[0] != shared {
+			t.Errorf("files = %v, want only %s", got, shared)
+		}
+	})
+}
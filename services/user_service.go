@@ -107,7 +107,7 @@ func LoginUser(email, password string) (string, error) {
 		return "", errors.New("email is not verified")
 	}
 
-	token, err := utils.GenerateJWT(user.ID.Hex(), user.Email)
+	token, err := utils.GenerateJWT(user.ID.Hex(), user.Email, user.Role)
 	if err != nil {
 		return "", errors.New("failed to generate token")
 	}
@@ -125,7 +125,7 @@ func FindUserByEmail(email string) (*model.User, error) {
 	return &user, nil
 }
 
-func CreateGoogleUser(name, email string) error {
+func CreateGoogleUser(name, email string) (*model.User, error) {
 	user := model.User{
 		ID:         primitive.NewObjectID(),
 		Name:       name,
@@ -137,5 +137,8 @@ func CreateGoogleUser(name, email string) error {
 	}
 
 	_, err := userCollection.InsertOne(context.Background(), user)
-	return err
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
 }
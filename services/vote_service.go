@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"CROWD_MARKET/config"
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var voteCollection *mongo.Collection
+
+var (
+	ErrInvalidVote = errors.New("vote must be confirm or dispute")
+	ErrOwnReport   = errors.New("you cannot vote on your own report")
+)
+
+// Confidence tuning
+const (
+	confidenceHalfLife  = 14 * 24 * time.Hour // price information ages quickly
+	votePriorWeight     = 2.0                 // pseudo-votes pulling towards 0.5
+	voteShare           = 0.7                 // remainder comes from reporter standing
+	confidenceBatchSize = 500
+)
+
+func InitVoteService() {
+	voteCollection = config.DB.Collection("report_votes")
+	ensureIndexes(voteCollection, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "voter_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+	})
+}
+
+// ComputeConfidence scores a report in [0,1] from its votes, its age and its
+// reporter's standing. Votes are smoothed towards 0.5 so one early vote does
+// not swing the score, and the whole score halves every confidenceHalfLife.
+func ComputeConfidence(confirmations, disputes int, age time.Duration, standing float64) float64 {
+	votes := (float64(confirmations) + 0.5*votePriorWeight) / (float64(confirmations+disputes) + votePriorWeight)
+	if age < 0 {
+		age = 0
+	}
+	freshness := math.Pow(0.5, float64(age)/float64(confidenceHalfLife))
+
+	score := freshness * (voteShare*votes + (1-voteShare)*standing)
+	return math.Round(score*1000) / 1000
+}
+
+func productConfidence(p model.Product, standing float64) float64 {
+	age := time.Duration(0)
+	if p.CreatedAt != nil {
+		age = time.Since(*p.CreatedAt)
+	}
+	return ComputeConfidence(p.Confirmations, p.Disputes, age, standing)
+}
+
+// recountVotes refreshes a product's vote tallies and confidence
+func recountVotes(ctx context.Context, productID primitive.ObjectID) (*model.Product, error) {
+	counts := map[string]int{}
+	cursor, err := voteCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"product_id": productID}}},
+		{{Key: "$group", Value: bson.M{"_id": "$vote", "n": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Vote string `bson:"_id"`
+		N    int    `bson:"n"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.Vote] = row.N
+	}
+
+	var product model.Product
+	if err := productCollection.FindOne(ctx, bson.M{"_id": productID}).Decode(&product); err != nil {
+		return nil, err
+	}
+	product.Confirmations = counts[model.VoteConfirm]
+	product.Disputes = counts[model.VoteDispute]
+	product.Confidence = productConfidence(product, userReputation(ctx, product.UserID))
+
+	_, err = productCollection.UpdateOne(ctx, bson.M{"_id": productID}, bson.M{"$set": bson.M{
+		"confirmations": product.Confirmations,
+		"disputes":      product.Disputes,
+		"confidence":    product.Confidence,
+	}})
+	return &product, err
+}
+
+// 👍 Confirm or dispute a report. Voting again replaces the earlier vote.
+func CastVote(productID, voterID, vote string) (*model.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if vote != model.VoteConfirm && vote != model.VoteDispute {
+		return nil, ErrInvalidVote
+	}
+
+	product, err := GetProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	voterObjID, err := toObjectID(voterID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+	if product.UserID == voterObjID {
+		return nil, ErrOwnReport
+	}
+	if err := EnsureCanContribute(voterObjID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var previous model.ReportVote
+	err = voteCollection.FindOneAndUpdate(ctx,
+		bson.M{"product_id": product.ID, "voter_id": voterObjID},
+		bson.M{
+			"$set":         bson.M{"vote": vote, "updated_at": now},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before),
+	).Decode(&previous)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if previous.Vote != vote {
+		applyReputationEventAsync(product.UserID, voteEvent(previous.Vote, vote))
+	}
+
+	return recountVotes(ctx, product.ID)
+}
+
+// 🗑️ Withdraw the caller's vote on a report
+func RemoveVote(productID, voterID string) (*model.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	productObjID, err := toObjectID(productID)
+	if err != nil {
+		return nil, err
+	}
+	voterObjID, err := toObjectID(voterID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	var removed model.ReportVote
+	err = voteCollection.FindOneAndDelete(ctx, bson.M{"product_id": productObjID, "voter_id": voterObjID}).Decode(&removed)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.New("vote not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := recountVotes(ctx, productObjID)
+	if err != nil {
+		return nil, err
+	}
+	applyReputationEventAsync(product.UserID, voteEvent(removed.Vote, ""))
+	return product, nil
+}
+
+// RefreshConfidence recomputes the stored confidence of every product so
+// the age term keeps decaying between votes. Only products whose rounded
+// score changed are written, and only if a vote did not change it meanwhile,
+// so most listings keep their sort key between runs.
+func RefreshConfidence() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	cursor, err := productCollection.Find(ctx, bson.M{"deleted_at": nil}, options.Find().SetBatchSize(confidenceBatchSize))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	standings := map[primitive.ObjectID]float64{}
+	var writes []mongo.WriteModel
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+		_, err := productCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		writes = writes[:0]
+		return err
+	}
+
+	for cursor.Next(ctx) {
+		var p model.Product
+		if err := cursor.Decode(&p); err != nil {
+			return err
+		}
+		standing, ok := standings[p.UserID]
+		if !ok {
+			standing = userReputation(ctx, p.UserID)
+			standings[p.UserID] = standing
+		}
+		write := confidenceUpdate(p, standing)
+		if write == nil {
+			continue
+		}
+		writes = append(writes, write)
+		if len(writes) >= confidenceBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// confidenceUpdate rewrites a product's confidence if its score moved; nil
+// when it did not. The write matches the old score so a concurrent vote wins.
+func confidenceUpdate(p model.Product, standing float64) *mongo.UpdateOneModel {
+	confidence := productConfidence(p, standing)
+	if confidence == p.Confidence {
+		return nil
+	}
+	return mongo.NewUpdateOneModel().
+		SetFilter(bson.M{"_id": p.ID, "confidence": p.Confidence}).
+		SetUpdate(bson.M{"$set": bson.M{"confidence": confidence}})
+}
+
+// ⏱️ Run RefreshConfidence now and then on every tick
+func StartConfidenceRefresher(interval time.Duration) {
+	go func() {
+		for {
+			if err := RefreshConfidence(); err != nil {
+				log.Printf("⚠️ Confidence refresh failed: %v", err)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"CROWD_MARKET/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestConfidenceUpdateSkipsUnchangedScores(t *testing.T) {
+	created := time.Now()
+	product := model.Product{ID: testProductID(1), CreatedAt: &created, Confirmations: 3}
+	product.Confidence = productConfidence(product, neutralReputation)
+
+	if write := confidenceUpdate(product, neutralReputation); write != nil {
+		t.Fatalf("unchanged score rewritten: %+v", write)
+	}
+
+	stale := product
+	stale.Confidence = product.Confidence + 0.2
+	write := confidenceUpdate(stale, neutralReputation)
+	if write == nil {
+		t.Fatal("changed score not rewritten")
+	}
+	// Matching the old score keeps a concurrent vote from being overwritten
+	want := bson.M{"_id": stale.ID, "confidence": stale.Confidence}
+	if filter, ok := write.Filter.(bson.M); !ok || filter["_id"] != want["_id"] || filter["confidence"] != want["confidence"] {
+		t.Errorf("filter = %v, want %v", write.Filter, want)
+	}
+}
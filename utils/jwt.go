@@ -9,11 +9,16 @@ import (
 
 var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
 
-func GenerateJWT(userID, email string) (string, error) {
+func GenerateJWT(userID, email, role string) (string, error) {
+
+	if role == "" {
+		role = "user"
+	}
 
 	claims := jwt.MapClaims{
-		"user_Id": userID,
+		"user_id": userID,
 		"email":  email,
+		"role":   role,
 		"exp":	time.Now().Add(time.Hour * 72).Unix(),
 	}
 
@@ -25,4 +30,4 @@ func GenerateJWT(userID, email string) (string, error) {
 	}
 
 	return signedToken, nil
-}
\ No newline at end of file
+}